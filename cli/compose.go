@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dorneanu/gocial/internal/bulk"
+	"github.com/dorneanu/gocial/internal/entity"
+	registry "github.com/dorneanu/gocial/internal/provider"
+	"github.com/dorneanu/gocial/internal/share"
+	"github.com/gdamore/tcell/v2"
+	"github.com/go-playground/validator/v10"
+	"github.com/rivo/tview"
+	"github.com/urfave/cli/v2"
+)
+
+// composer holds the state of the terminal composer
+type composer struct {
+	shareService share.Service
+	identities   []entity.IdentityProvider
+	article      entity.ArticleShare
+	selected     map[string]bool
+	schedule     string
+	scheduleAt   *time.Time
+	send         bool
+}
+
+// compose runs a full-screen terminal UI for writing and sending a post
+func compose(c *cli.Context) error {
+	app, err := newApp(c)
+	if err != nil {
+		return cli.Exit(err, exitFailure)
+	}
+	idRepo, err := app.Identities()
+	if err != nil {
+		return cli.Exit(err, exitFailure)
+	}
+
+	// Cross-post platforms don't share links
+	identities := make([]entity.IdentityProvider, 0)
+	for _, id := range idRepo.GetAll() {
+		if p, ok := registry.Get(id.Provider); ok && p.NewRepository != nil {
+			identities = append(identities, id)
+		}
+	}
+	if len(identities) == 0 {
+		return cli.Exit("No identities found. Run \"gocial login <provider>\" first.", exitFailure)
+	}
+
+	cmp := &composer{
+		shareService: app.ShareService,
+		identities:   identities,
+		article: entity.ArticleShare{
+			URL:     postURL,
+			Title:   postTitle,
+			Comment: postComment,
+		},
+		selected: make(map[string]bool),
+	}
+	if err := cmp.run(); err != nil {
+		return cli.Exit(err, exitFailure)
+	}
+	if !cmp.send {
+		return nil
+	}
+
+	// Wait for the scheduled time
+	if cmp.scheduleAt != nil {
+		fmt.Printf("Scheduled for %s\n", cmp.scheduleAt.Format(time.RFC3339))
+		if err := bulk.WaitUntil(c.Context, *cmp.scheduleAt); err != nil {
+			return err
+		}
+	}
+
+	results := shareToProviders(cmp.shareService, idRepo, cmp.article)
+	printResults(results)
+	return exitCode(results)
+}
+
+// run shows the UI until the user sends the post or quits
+func (cmp *composer) run() error {
+	app := tview.NewApplication()
+
+	preview := tview.NewTextView().SetWrap(true)
+	preview.SetBorder(true).SetTitle(" Preview ")
+	status := tview.NewTextView()
+
+	update := func() {
+		preview.SetText(cmp.renderPreviews())
+	}
+
+	form := tview.NewForm()
+	form.AddInputField("URL", cmp.article.URL, 0, nil, func(text string) {
+		cmp.article.URL = text
+		update()
+	})
+	form.AddInputField("Title", cmp.article.Title, 0, nil, func(text string) {
+		cmp.article.Title = text
+		update()
+	})
+	form.AddInputField("Comment", cmp.article.Comment, 0, nil, func(text string) {
+		cmp.article.Comment = text
+		update()
+	})
+	for _, id := range cmp.identities {
+		if id.Provider == "reddit" {
+			form.AddInputField("Subreddits", cmp.article.Subreddits, 0, nil, func(text string) {
+				cmp.article.Subreddits = text
+				update()
+			})
+			break
+		}
+	}
+	for _, id := range cmp.identities {
+		provider := id.Provider
+		form.AddCheckbox(fmt.Sprintf("%s (%s)", provider, id.UserName), false, func(checked bool) {
+			cmp.selected[provider] = checked
+			update()
+		})
+	}
+	form.AddInputField("Schedule", "", 0, nil, func(text string) {
+		cmp.schedule = text
+	})
+	form.AddButton("Send", func() {
+		if err := cmp.validate(); err != nil {
+			status.SetText(fmt.Sprintf("Error: %s", err))
+			return
+		}
+		cmp.send = true
+		app.Stop()
+	})
+	form.AddButton("Quit", app.Stop)
+	form.SetBorder(true).SetTitle(" Compose ")
+
+	help := tview.NewTextView().SetText("Tab: next field  Space: toggle  Schedule: RFC3339 time or duration (e.g. 2h)  Esc: quit")
+	layout := tview.NewFlex().
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(form, 0, 1, true).
+			AddItem(help, 1, 0, false), 0, 1, true).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(preview, 0, 1, false).
+			AddItem(status, 2, 0, false), 0, 1, false)
+
+	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			app.Stop()
+			return nil
+		}
+		return event
+	})
+
+	update()
+	return app.SetRoot(layout, true).Run()
+}
+
+// selectedArticle returns the article with the selected providers
+func (cmp *composer) selectedArticle() entity.ArticleShare {
+	providers := make([]string, 0)
+	for _, id := range cmp.identities {
+		if cmp.selected[id.Provider] {
+			providers = append(providers, id.Provider)
+		}
+	}
+	article := cmp.article
+	article.Providers = strings.Join(providers, ",")
+	return article
+}
+
+// renderPreviews shows the payload and character count of every selected provider
+func (cmp *composer) renderPreviews() string {
+	article := cmp.selectedArticle()
+	if article.Providers == "" {
+		return "Select at least one account."
+	}
+
+	var b strings.Builder
+	for _, id := range cmp.identities {
+		if !cmp.selected[id.Provider] {
+			continue
+		}
+
+		fmt.Fprintf(&b, "== %s ==\n", id.Provider)
+		preview, err := cmp.shareService.PreviewArticle(article, id)
+		if err != nil {
+			fmt.Fprintf(&b, "Error: %s\n\n", err)
+			continue
+		}
+		if preview.MaxLength > 0 {
+			fmt.Fprintf(&b, "%d/%d characters\n", utf8.RuneCountInString(preview.Text), preview.MaxLength)
+		}
+		payload, _ := json.MarshalIndent(preview.Payload, "", "  ")
+		fmt.Fprintf(&b, "%s\n\n", payload)
+	}
+	return b.String()
+}
+
+// validate checks the article and parses the schedule time
+func (cmp *composer) validate() error {
+	article := cmp.selectedArticle()
+	if err := validator.New().Struct(article); err != nil {
+		return fmt.Errorf("Invalid article: %s", err)
+	}
+
+	cmp.scheduleAt = nil
+	if s := strings.TrimSpace(cmp.schedule); s != "" {
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			cmp.scheduleAt = &t
+		} else if d, err := time.ParseDuration(s); err == nil {
+			t := time.Now().Add(d)
+			cmp.scheduleAt = &t
+		} else {
+			return fmt.Errorf("Invalid schedule: %s", s)
+		}
+	}
+
+	cmp.article = article
+	return nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+// checkConfig prints all problems of the configuration. It fails if there
+// are any problems besides warnings.
+func checkConfig(c *cli.Context) error {
+	app, err := newApp(c)
+	if err != nil {
+		return cli.Exit(err, exitFailure)
+	}
+
+	errors := 0
+	for _, p := range app.Check() {
+		if !p.Warning {
+			errors++
+		}
+		fmt.Println(p)
+	}
+	if errors > 0 {
+		return cli.Exit(fmt.Sprintf("Configuration has %d error(s)", errors), exitFailure)
+	}
+	fmt.Println("Configuration is valid")
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/provider"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+// connect checks the credentials of a provider without OAuth (e.g. a
+// Telegram bot token) and persists the identity in the local identity store
+func connect(c *cli.Context) error {
+	name := c.Args().First()
+	if name == "" {
+		return fmt.Errorf("No provider given")
+	}
+
+	app, err := newApp(c)
+	if err != nil {
+		return err
+	}
+	p, ok := provider.Get(name)
+	if !ok {
+		return fmt.Errorf("Unknown provider: %s", name)
+	}
+	if p.Connect == nil {
+		return fmt.Errorf("%s doesn't support connect (use gocial login %s)", name, name)
+	}
+	idRepo, err := app.Identities()
+	if err != nil {
+		return err
+	}
+
+	// Tokens passed as arguments show up in process listings
+	token := c.String("token")
+	if token == "" {
+		if token, err = readToken(); err != nil {
+			return err
+		}
+	}
+
+	id, err := p.Connect(c.Context, entity.IdentityProvider{
+		Provider:    name,
+		AccessToken: token,
+		UserID:      c.String("target"),
+		Endpoint:    c.String("endpoint"),
+	})
+	if err != nil {
+		return fmt.Errorf("Couldn't connect to %s: %s", name, err)
+	}
+
+	if err := idRepo.Add(id, nil); err != nil {
+		return err
+	}
+	if err := idRepo.Save(); err != nil {
+		return fmt.Errorf("Couldn't save identities: %s", err)
+	}
+	fmt.Printf("Connected to %s as %s\n", name, id.UserName)
+	return nil
+}
+
+// readToken reads the access token from stdin without echoing it
+func readToken() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, "Access token: ")
+		token, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("Couldn't read token: %s", err)
+		}
+		return strings.TrimSpace(string(token)), nil
+	}
+
+	token, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && token == "" {
+		return "", fmt.Errorf("Couldn't read token: %s", err)
+	}
+	return strings.TrimSpace(token), nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/frontmatter"
+	"github.com/dorneanu/gocial/internal/provider"
+	"github.com/urfave/cli/v2"
+)
+
+// crossPostFile syndicates the full article of a Markdown file as draft to
+// blogging platforms. Drafts created by earlier runs are updated.
+func crossPostFile(c *cli.Context) error {
+	file := c.Args().First()
+	if file == "" {
+		return cli.Exit("No file given", exitFailure)
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Couldn't read file: %s", err), exitFailure)
+	}
+	doc, err := frontmatter.Parse(file, data)
+	if err != nil {
+		return cli.Exit(err, exitFailure)
+	}
+
+	app, err := newApp(c)
+	if err != nil {
+		return cli.Exit(err, exitFailure)
+	}
+	idRepo, err := app.Identities()
+	if err != nil {
+		return cli.Exit(err, exitFailure)
+	}
+
+	article, err := doc.CrossPost(fileBaseURL(c, app), fileSlug(file))
+	if err != nil {
+		return cli.Exit(err, exitFailure)
+	}
+	if article.Title == "" {
+		return cli.Exit("Article has no title", exitFailure)
+	}
+
+	// Without --provider all connected platforms are used
+	providers := c.StringSlice("provider")
+	if len(providers) == 0 {
+		for _, id := range idRepo.GetAll() {
+			if p, ok := provider.Get(id.Provider); ok && p.NewCrossPostRepository != nil {
+				providers = append(providers, id.Provider)
+			}
+		}
+	}
+	if len(providers) == 0 {
+		return cli.Exit("No platform connected. Run \"gocial connect devto\" first.", exitFailure)
+	}
+
+	failed := 0
+	for _, name := range providers {
+		var post entity.CrossPost
+		id, err := idRepo.GetByProvider(name, nil)
+		if err != nil {
+			err = fmt.Errorf("Couldn't get identity: %s", err)
+		} else {
+			post, err = app.CrossPostService.CrossPost(c.Context, article, id)
+		}
+
+		switch {
+		case err != nil:
+			failed++
+			fmt.Printf("%s\tFAILED\t%s\n", name, err)
+		case post.CreatedAt.Equal(post.UpdatedAt):
+			fmt.Printf("%s\tCREATED\t%s\t%s\n", name, post.RemoteID, post.URL)
+		default:
+			fmt.Printf("%s\tUPDATED\t%s\t%s\n", name, post.RemoteID, post.URL)
+		}
+	}
+
+	switch {
+	case failed == 0:
+		return nil
+	case failed == len(providers):
+		return cli.Exit("Couldn't cross-post article", exitFailure)
+	default:
+		return cli.Exit(fmt.Sprintf("Couldn't cross-post article to %d of %d platforms", failed, len(providers)), exitPartialFailure)
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/bulk"
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/identity"
+	"github.com/dorneanu/gocial/internal/provider"
+	"github.com/urfave/cli/v2"
+)
+
+// importResult is a single line of the results file
+type importResult struct {
+	Row      int    `json:"row"`
+	Provider string `json:"provider"`
+	ShareID  string `json:"share_id,omitempty"`
+	PostID   string `json:"post_id,omitempty"`
+	PostURL  string `json:"post_url,omitempty"`
+	Error    string `json:"error,omitempty"`
+	// Duplicate is set if the article was already shared
+	Duplicate bool `json:"duplicate,omitempty"`
+}
+
+// importArticles validates all rows of a CSV/JSONL file and shares them.
+// Scheduled rows are shared once their time has come.
+func importArticles(c *cli.Context) error {
+	file := c.Args().First()
+	if file == "" {
+		return cli.Exit("No file given", exitFailure)
+	}
+
+	app, err := newApp(c)
+	if err != nil {
+		return cli.Exit(err, exitFailure)
+	}
+	idRepo, err := app.Identities()
+	if err != nil {
+		return cli.Exit(err, exitFailure)
+	}
+
+	shares, err := readImportFile(file, c.String("format"), providerCheck(idRepo))
+	if err != nil {
+		return cli.Exit(err, exitFailure)
+	}
+	if c.Bool("validate") {
+		fmt.Printf("%d rows are valid\n", len(shares))
+		return nil
+	}
+	shareService := app.ShareService
+
+	resultsFile := c.String("results")
+	if resultsFile == "" {
+		resultsFile = file + ".results.jsonl"
+	}
+	out, err := os.Create(resultsFile)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Couldn't create results file: %s", err), exitFailure)
+	}
+	defer out.Close()
+	encoder := json.NewEncoder(out)
+
+	// Share in chronological order; unscheduled rows come first
+	sort.SliceStable(shares, func(i, j int) bool {
+		return scheduleTime(shares[i]).Before(scheduleTime(shares[j]))
+	})
+
+	pacer := bulk.NewPacer(c.Duration("delay"))
+	results := make([]shareResult, 0)
+	for _, s := range shares {
+		if s.ScheduleAt != nil {
+			fmt.Printf("Row %d is scheduled for %s\n", s.Row, s.ScheduleAt.Format(time.RFC3339))
+		}
+		if err := bulk.WaitUntil(c.Context, scheduleTime(s)); err != nil {
+			return err
+		}
+
+		for _, provider := range strings.Split(s.Article.Providers, ",") {
+			if err := pacer.Wait(c.Context, provider); err != nil {
+				return err
+			}
+
+			r := shareToProvider(shareService, idRepo, s.Article, provider)
+			results = append(results, r)
+			if r.Error != "" {
+				fmt.Printf("Row %d\t%s\tFAILED\t%s\n", s.Row, r.Provider, r.Error)
+			} else if r.Duplicate {
+				fmt.Printf("Row %d\t%s\tDUPLICATE\n", s.Row, r.Provider)
+			} else {
+				fmt.Printf("Row %d\t%s\tOK\t%s\n", s.Row, r.Provider, r.Entry.PostURL)
+			}
+
+			err := encoder.Encode(importResult{
+				Row:       s.Row,
+				Provider:  r.Provider,
+				ShareID:   r.Entry.ID,
+				PostID:    r.Entry.PostID,
+				PostURL:   r.Entry.PostURL,
+				Error:     r.Error,
+				Duplicate: r.Duplicate,
+			})
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("Couldn't write results: %s", err), exitFailure)
+			}
+		}
+	}
+	return exitCode(results)
+}
+
+// readImportFile parses and validates the import file. All row errors
+// are reported at once before anything is shared.
+func readImportFile(file, format string, check bulk.ProviderCheck) ([]entity.ScheduledShare, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't open file: %s", err)
+	}
+	defer f.Close()
+
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(file)), ".")
+	}
+
+	var shares []entity.ScheduledShare
+	var rowErrors []bulk.RowError
+	switch format {
+	case "csv":
+		shares, rowErrors, err = bulk.ParseCSV(f, check)
+	case "jsonl", "ndjson":
+		shares, rowErrors, err = bulk.ParseJSONL(f, check)
+	default:
+		return nil, fmt.Errorf("Unknown file format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rowErrors) > 0 {
+		for _, e := range rowErrors {
+			fmt.Fprintln(os.Stderr, e)
+		}
+		return nil, fmt.Errorf("%d invalid rows", len(rowErrors))
+	}
+	return shares, nil
+}
+
+// providerCheck checks that a provider is registered and that there is an
+// identity for it
+func providerCheck(idRepo identity.Repository) bulk.ProviderCheck {
+	return func(name string) error {
+		if _, ok := provider.Get(name); !ok {
+			return fmt.Errorf("Unknown provider: %s", name)
+		}
+		if _, err := idRepo.GetByProvider(name, nil); err != nil {
+			return fmt.Errorf("No identity for %s (run \"gocial authenticate\" or \"gocial connect\")", name)
+		}
+		return nil
+	}
+}
+
+// scheduleTime returns the time a share is due
+func scheduleTime(s entity.ScheduledShare) time.Time {
+	if s.ScheduleAt == nil {
+		return time.Time{}
+	}
+	return *s.ScheduleAt
+}
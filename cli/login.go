@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	"github.com/dorneanu/gocial/internal/oauth"
+	registry "github.com/dorneanu/gocial/internal/provider"
+	"github.com/urfave/cli/v2"
+)
+
+// login authenticates against a single provider using a loopback redirect
+// and persists the identity in the local identity store
+func login(c *cli.Context) error {
+	provider := c.Args().First()
+	if provider == "" {
+		return fmt.Errorf("No provider given")
+	}
+
+	app, err := newApp(c)
+	if err != nil {
+		return err
+	}
+	if p, ok := registry.Get(provider); ok && p.Connect != nil {
+		return fmt.Errorf("%s doesn't use OAuth (use gocial connect %s)", provider, provider)
+	}
+	conf, err := app.OAuthConfig(provider)
+	if err != nil {
+		return err
+	}
+	idRepo, err := app.Identities()
+	if err != nil {
+		return err
+	}
+
+	id, err := oauth.LoopbackLogin(c.Context, conf, app.SessionConfig(), func(url string) {
+		fmt.Printf("Open the following URL in your browser:\n\n  %s\n\n", url)
+		openBrowser(url)
+	})
+	if err != nil {
+		return fmt.Errorf("Couldn't login to %s: %s", provider, err)
+	}
+
+	if err := idRepo.Add(id, nil); err != nil {
+		return err
+	}
+	if err := idRepo.Save(); err != nil {
+		return fmt.Errorf("Couldn't save identities: %s", err)
+	}
+	fmt.Printf("Logged in to %s as %s\n", provider, id.UserName)
+	return nil
+}
+
+// openBrowser tries to open url in the default browser
+func openBrowser(url string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	cmd.Start()
+}
@@ -6,9 +6,9 @@ import (
 	"os"
 	"time"
 
-	"github.com/dorneanu/gocial/internal/identity"
-	"github.com/dorneanu/gocial/internal/oauth"
-	"github.com/dorneanu/gocial/internal/share"
+	"github.com/dorneanu/gocial/internal/bootstrap"
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
 	"github.com/dorneanu/gocial/server"
 	"github.com/labstack/echo/v4"
 	"github.com/urfave/cli/v2"
@@ -18,11 +18,21 @@ var (
 	postURL     string
 	postTitle   string
 	postComment string
+	postInput   string
+
+	statsInterval time.Duration
 )
 
 func main() {
 	app := &cli.App{
-		// Flags: globalFlags,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "config",
+				Aliases: []string{"c"},
+				Usage:   "Load configuration from YAML file",
+				EnvVars: []string{"GOCIAL_CONFIG"},
+			},
+		},
 		Authors: []*cli.Author{
 			&cli.Author{
 				Name:  "Victor Dorneanu",
@@ -38,56 +48,14 @@ func main() {
 				Aliases: []string{"a"},
 				Usage:   "Authenticate against identity providers",
 				Action: func(c *cli.Context) error {
-					webServerConf := server.HTTPServerConfig{
-						ListenAddr:      "127.0.0.1:3000",
-						TokenSigningKey: "secret key",
-						TokenExpiration: 5,
-					}
-
-					// Create OAuth configs for different providers
-					oauthConfigs := []oauth.OAuthConfig{
-						oauth.OAuthConfig{
-							ProviderName: "linkedin",
-							Scopes:       []string{"r_emailaddress", "r_liteprofile", "w_member_social"},
-							ClientID:     os.Getenv("LINKEDIN_CLIENT_ID"),
-							ClientSecret: os.Getenv("LINKEDIN_CLIENT_SECRET"),
-							CallbackURL:  fmt.Sprintf("http://%s/auth/callback/linkedin", webServerConf.ListenAddr),
-						},
-						oauth.OAuthConfig{
-							ProviderName: "twitter",
-							ClientID:     os.Getenv("TWITTER_CLIENT_KEY"),
-							ClientSecret: os.Getenv("TWITTER_CLIENT_SECRET"),
-							CallbackURL:  fmt.Sprintf("http://%s/auth/callback/twitter", webServerConf.ListenAddr),
-						},
-					}
-
-					// New identity repository
-					// TODO: Is this still needed
-					// idRepo := entity.NewFileIdentityRepo("./auth.yaml")
-
-					// New goth auth repository
-					providerIndex := oauth.SetupAuthProviders(oauthConfigs)
-					gothRepository := oauth.NewGothRepository(providerIndex, webServerConf.TokenSigningKey)
-
-					// New identity repository
-					cookieIdentityRepo := identity.NewCookieIdentityRepository(&identity.CookieIdentityOptions{
-						BaseCookieName:  "gocial",
-						TokenSigningKey: webServerConf.TokenSigningKey,
-					})
-
-					// New OAuth authentication service service
-					oauthService := oauth.NewService(
-						oauth.ServiceConfig{
-							Repo:          gothRepository,
-							ProviderIndex: providerIndex,
-						},
-					)
-
-					// New share service
-					webServerConf.OAuthService = oauthService
-					webServerConf.IdentityService = cookieIdentityRepo
-					webServerConf.ProviderIndex = &providerIndex
-					webServerConf.ShareService = share.NewShareService()
+					app, err := newApp(c)
+					if err != nil {
+						return err
+					}
+					webServerConf, err := app.HTTPServerConfig()
+					if err != nil {
+						return err
+					}
 
 					// New web server
 					e := echo.New()
@@ -100,6 +68,52 @@ func main() {
 					return nil
 				},
 			},
+			{
+				// config sub-command
+				Name:  "config",
+				Usage: "Inspect the configuration",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "check",
+						Usage:  "Validate the configuration",
+						Action: checkConfig,
+					},
+				},
+			},
+			{
+				// providers sub-command
+				Name:   "providers",
+				Usage:  "List supported providers",
+				Action: listProviders,
+			},
+			{
+				// login sub-command
+				Name:      "login",
+				Usage:     "Authenticate against an identity provider and store the identity locally",
+				ArgsUsage: "<provider>",
+				Action:    login,
+			},
+			{
+				// connect sub-command
+				Name:      "connect",
+				Usage:     "Store the credentials of a provider without OAuth (e.g. telegram, matrix, mastodon, nostr, devto)",
+				ArgsUsage: "<provider>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "target",
+						Usage: "Chat (telegram: ID or @channel), room (matrix: ID or #alias) or publication (hashnode: host or ID) to post to",
+					},
+					&cli.StringFlag{
+						Name:  "endpoint",
+						Usage: "API URL (matrix: homeserver, mastodon: instance, devto: Forem instance)",
+					},
+					&cli.StringFlag{
+						Name:  "token",
+						Usage: "Bot or access token, API key or nsec (read from stdin if not set)",
+					},
+				},
+				Action: connect,
+			},
 			{
 				// post sub-command
 				Name:    "post",
@@ -120,45 +134,285 @@ func main() {
 						Usage:       "Post commentary",
 						Destination: &postComment,
 					},
+					&cli.StringSliceFlag{
+						Name:    "provider",
+						Aliases: []string{"p"},
+						Usage:   "Provider to share to (can be repeated)",
+					},
+					&cli.StringSliceFlag{
+						Name:  "subreddit",
+						Usage: "Subreddit to submit to, optionally with a flair as subreddit:flair (can be repeated)",
+					},
+					&cli.StringSliceFlag{
+						Name:  "tag",
+						Usage: "Tag recorded in the share history, e.g. for filtering the share feed (can be repeated)",
+					},
+					&cli.StringFlag{
+						Name:        "input",
+						Usage:       "Read article as JSON from file (\"-\" for stdin)",
+						Destination: &postInput,
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Print the payloads which would be sent instead of posting",
+					},
+				},
+				Usage:  "Post some article",
+				Action: postArticle,
+			},
+			{
+				// compose sub-command
+				Name:  "compose",
+				Usage: "Compose and send a post in a terminal UI",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:        "url",
+						Usage:       "URL",
+						Destination: &postURL,
+					},
+					&cli.StringFlag{
+						Name:        "title",
+						Usage:       "Post title",
+						Destination: &postTitle,
+					},
+					&cli.StringFlag{
+						Name:        "comment",
+						Usage:       "Post commentary",
+						Destination: &postComment,
+					},
+				},
+				Action: compose,
+			},
+			{
+				// share-file sub-command
+				Name:      "share-file",
+				Usage:     "Share a blog post described by its front matter",
+				ArgsUsage: "<file>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "base-url",
+						Usage: "Base URL the slug of the post is appended to (overrides the configured base URL)",
+					},
+					&cli.StringSliceFlag{
+						Name:    "provider",
+						Aliases: []string{"p"},
+						Usage:   "Provider to share to (overrides the front matter)",
+					},
+					&cli.BoolFlag{
+						Name:  "write-back",
+						Usage: "Write post permalinks back into the front matter as syndication links",
+					},
+				},
+				Action: shareFile,
+			},
+			{
+				// crosspost sub-command
+				Name:      "crosspost",
+				Usage:     "Create or update drafts of a Markdown blog post on blogging platforms (e.g. devto, hashnode)",
+				ArgsUsage: "<file>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "base-url",
+						Usage: "Base URL the slug of the post is appended to (overrides the configured base URL)",
+					},
+					&cli.StringSliceFlag{
+						Name:    "provider",
+						Aliases: []string{"p"},
+						Usage:   "Platform to cross-post to (default: all connected platforms)",
+					},
+				},
+				Action: crossPostFile,
+			},
+			{
+				// import sub-command
+				Name:      "import",
+				Usage:     "Share articles from a CSV or JSONL file",
+				ArgsUsage: "<file>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "format",
+						Usage: "File format (csv or jsonl); detected from the file extension by default",
+					},
+					&cli.DurationFlag{
+						Name:  "delay",
+						Usage: "Minimum delay between two posts to the same provider",
+						Value: 30 * time.Second,
+					},
+					&cli.StringFlag{
+						Name:  "results",
+						Usage: "File to write the results to (default: <file>.results.jsonl)",
+					},
+					&cli.BoolFlag{
+						Name:  "validate",
+						Usage: "Only validate the file",
+					},
 				},
-				Usage: "Post some article",
+				Action: importArticles,
+			},
+			{
+				// unshare sub-command
+				Name:      "unshare",
+				Usage:     "Delete already published posts",
+				ArgsUsage: "<share-id> [<share-id> ...]",
 				Action: func(c *cli.Context) error {
-					// authConf := entity.NewFileIdentityRepo("./auth.yaml")
-					// err := authConf.Load()
-					// if err != nil {
-					// 	return fmt.Errorf("Couldn't load auth details: %s", err)
-					// }
-
-					// article := entity.ArticleShare{
-					// 	URL:     postURL,
-					// 	Title:   postTitle,
-					// 	Comment: postComment,
-					// }
-
-					// id, err := authConf.GetByProvider("twitter")
-					// if err != nil {
-					// 	return fmt.Errorf("Couldn't get identity: %s", err)
-					// }
-
-					// linkedShareRepo := share.NewLinkedinShareRepository(id)
-					// oauth2 configures a client that uses app credentials to keep a fresh token
-					// twitterConfig := &share.TwitterConfig{
-					// 	ConsumerKey:    os.Getenv("TWITTER_CLIENT_KEY"),
-					// 	ConsumerSecret: os.Getenv("TWITTER_CLIENT_SECRET"),
-					// 	AccessToken:    id.AccessToken,
-					// 	AccessSecret:   id.AccessTokenSecret,
-					// }
-					// twitterShareRepo := share.NewTwitterShareRepository(twitterConfig)
-
-					// New share service
-					// shareService := share.NewShareService(twitterShareRepo)
-					// err = shareService.ShareArticle(article)
-					// if err != nil {
-					// 	return fmt.Errorf("Couldn't share article: %s", err)
-					// }
+					if c.NArg() == 0 {
+						return fmt.Errorf("No share ID given")
+					}
+
+					app, err := newApp(c)
+					if err != nil {
+						return err
+					}
+					idRepo, err := app.Identities()
+					if err != nil {
+						return err
+					}
+					shareService := app.ShareService
+
+					for _, shareID := range c.Args().Slice() {
+						entry, err := shareService.GetShare(shareID)
+						if err != nil {
+							return err
+						}
+						id, err := idRepo.GetByProvider(entry.Provider, nil)
+						if err != nil {
+							return fmt.Errorf("Couldn't get identity: %s", err)
+						}
+						if err := shareService.DeleteShare(entry, id); err != nil {
+							return fmt.Errorf("Couldn't delete %s (%s): %s", shareID, entry.Provider, err)
+						}
+						fmt.Printf("Deleted %s (%s)\n", shareID, entry.Provider)
+					}
+					return nil
+				},
+			},
+			{
+				// edit sub-command
+				Name:      "edit",
+				Usage:     "Change title and comment of an already published post",
+				ArgsUsage: "<share-id>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "title",
+						Usage: "New title (default: unchanged)",
+					},
+					&cli.StringFlag{
+						Name:  "comment",
+						Usage: "New comment (default: unchanged)",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					shareID := c.Args().First()
+					if shareID == "" {
+						return fmt.Errorf("No share ID given")
+					}
+
+					app, err := newApp(c)
+					if err != nil {
+						return err
+					}
+					idRepo, err := app.Identities()
+					if err != nil {
+						return err
+					}
+
+					entry, err := app.ShareService.GetShare(shareID)
+					if err != nil {
+						return err
+					}
+					id, err := idRepo.GetByProvider(entry.Provider, nil)
+					if err != nil {
+						return fmt.Errorf("Couldn't get identity: %s", err)
+					}
+
+					article := entity.ArticleShare{Title: entry.Title, Comment: entry.Comment}
+					if c.IsSet("title") {
+						article.Title = c.String("title")
+					}
+					if c.IsSet("comment") {
+						article.Comment = c.String("comment")
+					}
+					if err := app.ShareService.EditShare(entry, article, id); err != nil {
+						return fmt.Errorf("Couldn't edit %s (%s): %s", shareID, entry.Provider, err)
+					}
+					fmt.Printf("Edited %s (%s)\n", shareID, entry.Provider)
 					return nil
 				},
 			},
+			{
+				// watch sub-command
+				Name:  "watch",
+				Usage: "Watch feeds and share new entries",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:  "feed",
+						Usage: "URL of RSS/Atom/JSON feed (can be repeated, overrides the configured feeds)",
+					},
+					&cli.StringSliceFlag{
+						Name:    "provider",
+						Aliases: []string{"p"},
+						Usage:   "Provider to share to (can be repeated)",
+					},
+					&cli.StringFlag{
+						Name:  "template",
+						Usage: "Template for the comment (e.g. \"New post: {{.Title}}\")",
+					},
+					&cli.DurationFlag{
+						Name:  "delay",
+						Usage: "Time to wait before new entries of --feed are shared (e.g. 2h)",
+					},
+					&cli.DurationFlag{
+						Name:  "interval",
+						Usage: "Poll interval (overrides the configured interval)",
+					},
+					&cli.BoolFlag{
+						Name:  "once",
+						Usage: "Poll only once and exit",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Only show what would be shared",
+					},
+				},
+				Action: watchFeeds,
+			},
+			{
+				// stats sub-command
+				Name:  "stats",
+				Usage: "Collect engagement metrics of shared posts",
+				Flags: []cli.Flag{
+					&cli.DurationFlag{
+						Name:        "interval",
+						Usage:       "Collect metrics periodically (e.g. 1h)",
+						Destination: &statsInterval,
+					},
+				},
+				Action: func(c *cli.Context) error {
+					app, err := newApp(c)
+					if err != nil {
+						return err
+					}
+					idRepo, err := app.Identities()
+					if err != nil {
+						return err
+					}
+					metricsService := app.MetricsService
+
+					// Collect periodically
+					if statsInterval > 0 {
+						metricsService.Run(c.Context, statsInterval, idRepo.GetAll())
+						return nil
+					}
+
+					// Collect once
+					snapshots, err := metricsService.Collect(c.Context, idRepo.GetAll())
+					for _, s := range snapshots {
+						fmt.Printf("%s\t%s\tlikes: %d\treposts: %d\tcomments: %d\n",
+							s.ShareID, s.Provider, s.Metrics.Likes, s.Metrics.Reposts, s.Metrics.Comments)
+					}
+					return err
+				},
+			},
 		},
 	}
 	err := app.Run(os.Args)
@@ -166,3 +420,12 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// newApp builds the services from the configuration given by --config
+func newApp(c *cli.Context) (*bootstrap.App, error) {
+	conf, err := config.Load(c.String("config"))
+	if err != nil {
+		return nil, err
+	}
+	return bootstrap.New(conf)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/identity"
+	"github.com/dorneanu/gocial/internal/share"
+	"github.com/go-playground/validator/v10"
+	"github.com/urfave/cli/v2"
+)
+
+// Exit codes of the post command
+const (
+	exitFailure        = 1
+	exitPartialFailure = 2
+)
+
+// postArticle shares an article to all given providers using the identities
+// from the local identity store
+func postArticle(c *cli.Context) error {
+	article, err := readArticle(c)
+	if err != nil {
+		return cli.Exit(err, exitFailure)
+	}
+
+	app, err := newApp(c)
+	if err != nil {
+		return cli.Exit(err, exitFailure)
+	}
+	idRepo, err := app.Identities()
+	if err != nil {
+		return cli.Exit(err, exitFailure)
+	}
+	shareService := app.ShareService
+
+	if c.Bool("dry-run") {
+		return previewArticle(shareService, idRepo, article)
+	}
+
+	results := shareToProviders(shareService, idRepo, article)
+	printResults(results)
+	return exitCode(results)
+}
+
+// previewArticle prints the payload which would be sent to every provider
+func previewArticle(shareService share.Service, idRepo identity.Repository, article entity.ArticleShare) error {
+	previews := make([]entity.SharePreview, 0)
+	results := make([]shareResult, 0)
+	for _, provider := range strings.Split(article.Providers, ",") {
+		result := shareResult{Provider: provider}
+
+		id, err := idRepo.GetByProvider(provider, nil)
+		if err != nil {
+			result.Error = fmt.Sprintf("Couldn't get identity: %s", err)
+		} else if preview, err := shareService.PreviewArticle(article, id); err != nil {
+			result.Error = err.Error()
+		} else {
+			previews = append(previews, preview)
+		}
+
+		if result.Error != "" {
+			fmt.Fprintf(os.Stderr, "%s\tFAILED\t%s\n", provider, result.Error)
+		}
+		results = append(results, result)
+	}
+
+	out, err := json.MarshalIndent(previews, "", "  ")
+	if err != nil {
+		return cli.Exit(err, exitFailure)
+	}
+	fmt.Println(string(out))
+	return exitCode(results)
+}
+
+// shareResult is the outcome of sharing an article via a single provider
+type shareResult struct {
+	Provider string
+	Entry    entity.ShareEntry
+	Error    string
+	// Duplicate is set if the provider rejected the article as already
+	// shared. Duplicates don't count as failures.
+	Duplicate bool
+}
+
+// shareToProviders shares article to every provider in article.Providers
+func shareToProviders(shareService share.Service, idRepo identity.Repository, article entity.ArticleShare) []shareResult {
+	results := make([]shareResult, 0)
+	for _, provider := range strings.Split(article.Providers, ",") {
+		results = append(results, shareToProvider(shareService, idRepo, article, provider))
+	}
+	return results
+}
+
+// shareToProvider shares article to a single provider
+func shareToProvider(shareService share.Service, idRepo identity.Repository, article entity.ArticleShare, provider string) shareResult {
+	result := shareResult{Provider: provider}
+
+	id, err := idRepo.GetByProvider(provider, nil)
+	if err != nil {
+		result.Error = fmt.Sprintf("Couldn't get identity: %s", err)
+		return result
+	}
+
+	result.Entry, err = shareService.ShareArticle(article, id)
+	if errors.Is(err, share.ErrDuplicate) {
+		result.Duplicate = true
+	} else if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func printResults(results []shareResult) {
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Printf("%s\tFAILED\t%s\n", r.Provider, r.Error)
+			continue
+		}
+		if r.Duplicate {
+			fmt.Printf("%s\tDUPLICATE\n", r.Provider)
+			continue
+		}
+		fmt.Printf("%s\tOK\t%s\t%s\n", r.Provider, r.Entry.ID, r.Entry.PostURL)
+		for _, d := range r.Entry.Deliveries {
+			status := "ACCEPTED"
+			if !d.Accepted {
+				status = "REJECTED"
+			}
+			fmt.Printf("\t%s\t%s\t%s\n", status, d.Target, d.Message)
+		}
+	}
+}
+
+// exitCode returns nil if all shares succeeded and an exit error otherwise
+func exitCode(results []shareResult) error {
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+		}
+	}
+
+	switch {
+	case failed == 0:
+		return nil
+	case failed == len(results):
+		return cli.Exit("Couldn't share article", exitFailure)
+	default:
+		return cli.Exit(fmt.Sprintf("Couldn't share article to %d of %d providers", failed, len(results)), exitPartialFailure)
+	}
+}
+
+// readArticle builds the article from the JSON input (if any) and the
+// command line flags. Flags take precedence over the JSON input.
+func readArticle(c *cli.Context) (entity.ArticleShare, error) {
+	article := entity.ArticleShare{}
+
+	if postInput != "" {
+		var r io.Reader = os.Stdin
+		if postInput != "-" {
+			f, err := os.Open(postInput)
+			if err != nil {
+				return article, fmt.Errorf("Couldn't open input: %s", err)
+			}
+			defer f.Close()
+			r = f
+		}
+		if err := json.NewDecoder(r).Decode(&article); err != nil {
+			return article, fmt.Errorf("Couldn't unmarshalize input: %s", err)
+		}
+	}
+
+	if postURL != "" {
+		article.URL = postURL
+	}
+	if postTitle != "" {
+		article.Title = postTitle
+	}
+	if postComment != "" {
+		article.Comment = postComment
+	}
+	if providers := c.StringSlice("provider"); len(providers) > 0 {
+		article.Providers = strings.Join(providers, ",")
+	}
+	if subreddits := c.StringSlice("subreddit"); len(subreddits) > 0 {
+		article.Subreddits = strings.Join(subreddits, ",")
+	}
+	if tags := c.StringSlice("tag"); len(tags) > 0 {
+		article.Tags = strings.Join(tags, ",")
+	}
+
+	if err := validator.New().Struct(article); err != nil {
+		return article, fmt.Errorf("Invalid article: %s", err)
+	}
+	return article, nil
+}
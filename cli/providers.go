@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dorneanu/gocial/internal/provider"
+	"github.com/urfave/cli/v2"
+)
+
+// listProviders prints all registered providers and their capabilities
+func listProviders(c *cli.Context) error {
+	// Plugins and configured providers are registered when the app is built
+	if _, err := newApp(c); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tDISPLAY NAME\tMAX LENGTH\tCAPABILITIES")
+	for _, p := range provider.All() {
+		caps := make([]string, 0)
+		if p.OAuth != nil {
+			caps = append(caps, "oauth")
+		}
+		if p.Connect != nil {
+			caps = append(caps, "connect")
+		}
+		for _, capability := range []struct {
+			name string
+			ok   bool
+		}{
+			{"media", p.Capabilities.Media},
+			{"threads", p.Capabilities.Threads},
+			{"edit", p.Capabilities.Edit},
+			{"delete", p.Capabilities.Delete},
+			{"metrics", p.Capabilities.Metrics},
+			{"crosspost", p.Capabilities.CrossPost},
+		} {
+			if capability.ok {
+				caps = append(caps, capability.name)
+			}
+		}
+
+		maxLength := "-"
+		if p.Capabilities.MaxLength > 0 {
+			maxLength = fmt.Sprint(p.Capabilities.MaxLength)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Name, p.DisplayName, maxLength, strings.Join(caps, ","))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/dorneanu/gocial/internal/bootstrap"
+	"github.com/dorneanu/gocial/internal/frontmatter"
+	"github.com/go-playground/validator/v10"
+	"github.com/urfave/cli/v2"
+)
+
+// shareFile shares the blog post described by the front matter of a
+// Markdown or Org file
+func shareFile(c *cli.Context) error {
+	file := c.Args().First()
+	if file == "" {
+		return cli.Exit("No file given", exitFailure)
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Couldn't read file: %s", err), exitFailure)
+	}
+	doc, err := frontmatter.Parse(file, data)
+	if err != nil {
+		return cli.Exit(err, exitFailure)
+	}
+
+	app, err := newApp(c)
+	if err != nil {
+		return cli.Exit(err, exitFailure)
+	}
+	idRepo, err := app.Identities()
+	if err != nil {
+		return cli.Exit(err, exitFailure)
+	}
+	shareService := app.ShareService
+
+	article, err := doc.Article(fileBaseURL(c, app), fileSlug(file))
+	if err != nil {
+		return cli.Exit(err, exitFailure)
+	}
+	if providers := c.StringSlice("provider"); len(providers) > 0 {
+		article.Providers = strings.Join(providers, ",")
+	}
+	if err := validator.New().Struct(article); err != nil {
+		return cli.Exit(fmt.Sprintf("Invalid article: %s", err), exitFailure)
+	}
+
+	results := shareToProviders(shareService, idRepo, article)
+	printResults(results)
+
+	if c.Bool("write-back") {
+		links := make([]string, 0)
+		for _, r := range results {
+			if r.Error == "" && r.Entry.PostURL != "" {
+				links = append(links, r.Entry.PostURL)
+			}
+		}
+		if len(links) > 0 {
+			content, err := doc.AddSyndication(links...)
+			if err != nil {
+				return cli.Exit(err, exitFailure)
+			}
+			if err := ioutil.WriteFile(file, content, 0644); err != nil {
+				return cli.Exit(fmt.Sprintf("Couldn't write file: %s", err), exitFailure)
+			}
+		}
+	}
+	return exitCode(results)
+}
+
+// fileSlug returns the slug of last resort: the file name or, for page
+// bundles (index.md), the directory name
+func fileSlug(file string) string {
+	slug := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	if slug == "index" {
+		slug = filepath.Base(filepath.Dir(file))
+	}
+	return slug
+}
+
+// fileBaseURL returns the base URL of blog posts
+func fileBaseURL(c *cli.Context, app *bootstrap.App) string {
+	if baseURL := c.String("base-url"); baseURL != "" {
+		return baseURL
+	}
+	return app.Config.ShareFile.BaseURL
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+// watchFeeds polls feeds and shares new entries to the given providers.
+// Feeds given on the command line replace the configured ones.
+func watchFeeds(c *cli.Context) error {
+	app, err := newApp(c)
+	if err != nil {
+		return err
+	}
+	idRepo, err := app.Identities()
+	if err != nil {
+		return err
+	}
+
+	conf := app.Config.Watch
+	if c.IsSet("feed") {
+		conf.Feeds = make([]config.FeedConfig, 0)
+		for _, url := range c.StringSlice("feed") {
+			conf.Feeds = append(conf.Feeds, config.FeedConfig{
+				URL:       url,
+				Providers: c.StringSlice("provider"),
+				Template:  c.String("template"),
+				Delay:     c.Duration("delay"),
+			})
+		}
+	}
+	if len(conf.Feeds) == 0 {
+		return fmt.Errorf("No feeds given")
+	}
+	for _, feed := range conf.Feeds {
+		if len(feed.Providers) == 0 {
+			return fmt.Errorf("No providers given for %s", feed.URL)
+		}
+		if feed.Delay < 0 {
+			return fmt.Errorf("Delay must not be negative")
+		}
+	}
+	if c.IsSet("interval") {
+		conf.Interval = c.Duration("interval")
+	}
+	if conf.Interval <= 0 && !c.Bool("once") {
+		return fmt.Errorf("Interval must be positive")
+	}
+	if c.Bool("dry-run") {
+		conf.DryRun = true
+	}
+	watchService := app.WatchService(conf, idRepo)
+
+	if !c.Bool("once") {
+		watchService.Run(c.Context)
+		return nil
+	}
+
+	shared, err := watchService.Poll(c.Context)
+	for _, article := range shared {
+		fmt.Printf("%s\t%s\n", article.Providers, article.URL)
+	}
+	return err
+}
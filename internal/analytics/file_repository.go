@@ -0,0 +1,182 @@
+package analytics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+// ErrLinkNotFound is returned when a tracking code is unknown
+var ErrLinkNotFound = errors.New("Tracking link not found")
+
+// maxCountsPerDay limits the click counts stored per day. Clicks with
+// referrers beyond the limit are counted as "other", so forged referrers
+// can't grow the file.
+const maxCountsPerDay = 1000
+
+// FileAnalyticsRepository implements analytics.Repository
+// and keeps links and daily click counts in a single JSON file
+type FileAnalyticsRepository struct {
+	BasePath string
+	// Retention is the time click counts are kept (0 keeps them forever)
+	Retention time.Duration
+	mu        sync.Mutex
+}
+
+type analyticsData struct {
+	Links  map[string]entity.TrackingLink `json:"links"`
+	Counts []entity.ClickCount            `json:"counts"`
+	// Clicks holds the single click events of older versions. They are
+	// converted into counts when the file is loaded.
+	Clicks []entity.ClickEvent `json:"clicks,omitempty"`
+}
+
+func NewFileAnalyticsRepository(path string, retention time.Duration) *FileAnalyticsRepository {
+	return &FileAnalyticsRepository{
+		BasePath:  path,
+		Retention: retention,
+	}
+}
+
+// AddLink stores a new tracking link
+func (fr *FileAnalyticsRepository) AddLink(link entity.TrackingLink) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	data, err := fr.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := data.Links[link.Code]; ok {
+		return fmt.Errorf("Tracking link already exists: %s", link.Code)
+	}
+	data.Links[link.Code] = link
+	return fr.save(data)
+}
+
+// GetLink returns the tracking link for code
+func (fr *FileAnalyticsRepository) GetLink(code string) (entity.TrackingLink, error) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	data, err := fr.load()
+	if err != nil {
+		return entity.TrackingLink{}, err
+	}
+	link, ok := data.Links[code]
+	if !ok {
+		return entity.TrackingLink{}, ErrLinkNotFound
+	}
+	return link, nil
+}
+
+// AddClick increments the count of the click's day, link, referrer and
+// device class. Counts older than the retention are dropped.
+func (fr *FileAnalyticsRepository) AddClick(click entity.ClickEvent) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	data, err := fr.load()
+	if err != nil {
+		return err
+	}
+	if fr.Retention > 0 {
+		data.Counts = prune(data.Counts, time.Now().Add(-fr.Retention))
+	}
+	data.Counts = addClick(data.Counts, click)
+	return fr.save(data)
+}
+
+// GetClickCounts returns all stored click counts
+func (fr *FileAnalyticsRepository) GetClickCounts() ([]entity.ClickCount, error) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	data, err := fr.load()
+	if err != nil {
+		return nil, err
+	}
+	return data.Counts, nil
+}
+
+// addClick adds click to the matching count or appends a new one
+func addClick(counts []entity.ClickCount, click entity.ClickEvent) []entity.ClickCount {
+	key := entity.ClickCount{
+		Day:       click.Timestamp.UTC().Format("2006-01-02"),
+		Code:      click.Code,
+		ShareID:   click.ShareID,
+		Provider:  click.Provider,
+		Referrer:  click.Referrer,
+		UserAgent: click.UserAgent,
+	}
+
+	perDay := 0
+	for i := range counts {
+		c := counts[i]
+		if c.Day != key.Day {
+			continue
+		}
+		perDay++
+		c.Count = 0
+		if c == key {
+			counts[i].Count++
+			return counts
+		}
+	}
+
+	if perDay >= maxCountsPerDay && key.Referrer != "other" {
+		click.Referrer = "other"
+		return addClick(counts, click)
+	}
+	key.Count = 1
+	return append(counts, key)
+}
+
+// prune drops the counts of days before since
+func prune(counts []entity.ClickCount, since time.Time) []entity.ClickCount {
+	first := since.UTC().Format("2006-01-02")
+	kept := counts[:0]
+	for _, c := range counts {
+		if c.Day >= first {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+func (fr *FileAnalyticsRepository) load() (analyticsData, error) {
+	data := analyticsData{
+		Links:  make(map[string]entity.TrackingLink),
+		Counts: make([]entity.ClickCount, 0),
+	}
+
+	b, err := ioutil.ReadFile(fr.BasePath)
+	if os.IsNotExist(err) {
+		return data, nil
+	} else if err != nil {
+		return data, fmt.Errorf("Couldn't open file: %s", err)
+	}
+
+	if err := json.Unmarshal(b, &data); err != nil {
+		return data, fmt.Errorf("Couldn't unmarshalize data: %s", err)
+	}
+	for _, click := range data.Clicks {
+		data.Counts = addClick(data.Counts, click)
+	}
+	data.Clicks = nil
+	return data, nil
+}
+
+func (fr *FileAnalyticsRepository) save(data analyticsData) error {
+	b, err := json.MarshalIndent(data, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fr.BasePath, b, 0600)
+}
@@ -0,0 +1,83 @@
+package analytics
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+func TestFileAnalyticsRepositoryCountsClicks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "analytics.json")
+	legacy := `{"links": {}, "clicks": [
+		{"code": "a", "share_id": "1", "provider": "twitter", "referrer": "t.co", "user_agent": "mobile", "timestamp": "2026-10-18T10:00:00Z"},
+		{"code": "a", "share_id": "1", "provider": "twitter", "referrer": "t.co", "user_agent": "mobile", "timestamp": "2026-10-18T11:00:00Z"}
+	]}`
+	if err := ioutil.WriteFile(path, []byte(legacy), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewFileAnalyticsRepository(path, 0)
+	click := entity.ClickEvent{Code: "a", ShareID: "1", Provider: "twitter", Referrer: "t.co", UserAgent: "mobile"}
+	for _, ts := range []string{"2026-10-18T23:00:00Z", "2026-10-19T01:00:00Z"} {
+		click.Timestamp, _ = time.Parse(time.RFC3339, ts)
+		if err := repo.AddClick(click); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	counts, err := repo.GetClickCounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]int)
+	for _, c := range counts {
+		got[c.Day] += c.Count
+	}
+	if len(counts) != 2 || got["2026-10-18"] != 3 || got["2026-10-19"] != 1 {
+		t.Errorf("counts = %+v; want 3 clicks on 2026-10-18 and 1 on 2026-10-19", counts)
+	}
+
+	b, _ := ioutil.ReadFile(path)
+	if data := string(b); len(data) == 0 || strings.Contains(data, `"clicks"`) {
+		t.Errorf("single click events are still stored:\n%s", data)
+	}
+}
+
+func TestFileAnalyticsRepositoryRetention(t *testing.T) {
+	repo := NewFileAnalyticsRepository(filepath.Join(t.TempDir(), "analytics.json"), 48*time.Hour)
+	for _, age := range []time.Duration{10 * 24 * time.Hour, 3 * 24 * time.Hour, 0} {
+		err := repo.AddClick(entity.ClickEvent{Code: "a", Timestamp: time.Now().Add(-age)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	counts, err := repo.GetClickCounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 1 || counts[0].Day != time.Now().UTC().Format("2006-01-02") {
+		t.Errorf("counts = %+v; want only today", counts)
+	}
+}
+
+func TestAddClickLimitsCountsPerDay(t *testing.T) {
+	counts := make([]entity.ClickCount, 0)
+	now := time.Now()
+	for i := 0; i < maxCountsPerDay+50; i++ {
+		counts = addClick(counts, entity.ClickEvent{Code: "a", Referrer: fmt.Sprintf("r%d.example", i), Timestamp: now})
+	}
+
+	if len(counts) != maxCountsPerDay+1 {
+		t.Fatalf("len(counts) = %d; want %d", len(counts), maxCountsPerDay+1)
+	}
+	other := counts[len(counts)-1]
+	if other.Referrer != "other" || other.Count != 50 {
+		t.Errorf("last count = %+v; want 50 clicks of referrer other", other)
+	}
+}
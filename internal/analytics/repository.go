@@ -0,0 +1,11 @@
+package analytics
+
+import "github.com/dorneanu/gocial/internal/entity"
+
+// Repository stores tracking links and the clicks on them
+type Repository interface {
+	AddLink(entity.TrackingLink) error
+	GetLink(string) (entity.TrackingLink, error)
+	AddClick(entity.ClickEvent) error
+	GetClickCounts() ([]entity.ClickCount, error)
+}
@@ -0,0 +1,148 @@
+package analytics
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+type Service interface {
+	NewTrackingLink(shareID, provider, longURL string) (string, error)
+	PreviewTrackingLink() (string, error)
+	Click(code, referrer, userAgent string) (string, error)
+	Summary() (entity.AnalyticsSummary, error)
+}
+
+type ServiceConfig struct {
+	Repo Repository
+	// BaseURL is the public URL gocial is reachable at
+	BaseURL string
+}
+
+// analyticsService implements analytics.Service
+type analyticsService struct {
+	repo    Repository
+	baseURL string
+}
+
+func NewService(conf ServiceConfig) Service {
+	return analyticsService{
+		repo:    conf.Repo,
+		baseURL: strings.TrimSuffix(conf.BaseURL, "/"),
+	}
+}
+
+// NewTrackingLink creates a tracking link for a single share and provider
+// and returns its public URL
+func (s analyticsService) NewTrackingLink(shareID, provider, longURL string) (string, error) {
+	code, err := newTrackingCode()
+	if err != nil {
+		return "", err
+	}
+
+	link := entity.TrackingLink{
+		Code:      code,
+		ShareID:   shareID,
+		Provider:  provider,
+		URL:       longURL,
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.AddLink(link); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/t/%s", s.baseURL, link.Code), nil
+}
+
+// PreviewTrackingLink returns a tracking link which looks like the ones
+// created by NewTrackingLink but isn't stored
+func (s analyticsService) PreviewTrackingLink() (string, error) {
+	code, err := newTrackingCode()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/t/%s", s.baseURL, code), nil
+}
+
+// newTrackingCode returns a random tracking code
+func newTrackingCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("Couldn't generate tracking code: %s", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Click records an anonymized click event and returns the URL to redirect to
+func (s analyticsService) Click(code, referrer, userAgent string) (string, error) {
+	link, err := s.repo.GetLink(code)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.repo.AddClick(entity.ClickEvent{
+		Code:      link.Code,
+		ShareID:   link.ShareID,
+		Provider:  link.Provider,
+		Referrer:  referrerHost(referrer),
+		UserAgent: coarseUserAgent(userAgent),
+		Timestamp: time.Now().UTC().Truncate(time.Hour),
+	})
+	if err != nil {
+		return "", err
+	}
+	return link.URL, nil
+}
+
+// Summary aggregates all click counts
+func (s analyticsService) Summary() (entity.AnalyticsSummary, error) {
+	summary := entity.AnalyticsSummary{
+		ByProvider:  make(map[string]int),
+		ByShare:     make(map[string]int),
+		ByReferrer:  make(map[string]int),
+		ByUserAgent: make(map[string]int),
+		ByDay:       make(map[string]int),
+	}
+
+	counts, err := s.repo.GetClickCounts()
+	if err != nil {
+		return summary, err
+	}
+	for _, c := range counts {
+		summary.TotalClicks += c.Count
+		summary.ByProvider[c.Provider] += c.Count
+		summary.ByShare[c.ShareID] += c.Count
+		summary.ByReferrer[c.Referrer] += c.Count
+		summary.ByUserAgent[c.UserAgent] += c.Count
+		summary.ByDay[c.Day] += c.Count
+	}
+	return summary, nil
+}
+
+// referrerHost strips everything but the host from the referrer
+func referrerHost(referrer string) string {
+	u, err := url.Parse(referrer)
+	if err != nil || u.Host == "" {
+		return "direct"
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// coarseUserAgent reduces a user agent to its device class
+func coarseUserAgent(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case ua == "":
+		return "unknown"
+	case strings.Contains(ua, "bot"), strings.Contains(ua, "crawler"), strings.Contains(ua, "spider"):
+		return "bot"
+	case strings.Contains(ua, "mobile"), strings.Contains(ua, "android"), strings.Contains(ua, "iphone"):
+		return "mobile"
+	default:
+		return "desktop"
+	}
+}
@@ -0,0 +1,340 @@
+// Package bootstrap builds the gocial services from the configuration. It is
+// shared by all entry points (CLI, server and Lambda).
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/analytics"
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/crosspost"
+	"github.com/dorneanu/gocial/internal/feed"
+	"github.com/dorneanu/gocial/internal/history"
+	"github.com/dorneanu/gocial/internal/identity"
+	"github.com/dorneanu/gocial/internal/metrics"
+	"github.com/dorneanu/gocial/internal/oauth"
+	"github.com/dorneanu/gocial/internal/provider"
+	_ "github.com/dorneanu/gocial/internal/provider/devto"
+	"github.com/dorneanu/gocial/internal/provider/discord"
+	"github.com/dorneanu/gocial/internal/provider/email"
+	_ "github.com/dorneanu/gocial/internal/provider/hashnode"
+	_ "github.com/dorneanu/gocial/internal/provider/linkedin"
+	"github.com/dorneanu/gocial/internal/provider/mastodon"
+	"github.com/dorneanu/gocial/internal/provider/matrix"
+	"github.com/dorneanu/gocial/internal/provider/nostr"
+	"github.com/dorneanu/gocial/internal/provider/plugin"
+	"github.com/dorneanu/gocial/internal/provider/reddit"
+	"github.com/dorneanu/gocial/internal/provider/slack"
+	"github.com/dorneanu/gocial/internal/provider/telegram"
+	_ "github.com/dorneanu/gocial/internal/provider/twitter"
+	"github.com/dorneanu/gocial/internal/provider/webhook"
+	"github.com/dorneanu/gocial/internal/secret"
+	"github.com/dorneanu/gocial/internal/share"
+	"github.com/dorneanu/gocial/internal/shortener"
+	"github.com/dorneanu/gocial/internal/watch"
+	"github.com/dorneanu/gocial/server"
+)
+
+// App holds the services built from a configuration
+type App struct {
+	Config           *config.Config
+	History          history.Repository
+	Shortener        shortener.Shortener
+	AnalyticsService analytics.Service
+	ShareService     share.Service
+	MetricsService   metrics.Service
+	CrossPostService crosspost.Service
+}
+
+// New resolves the secrets of the configuration and builds the services
+// which don't depend on the entry point
+func New(conf *config.Config) (*App, error) {
+	if err := resolveSecrets(conf); err != nil {
+		return nil, err
+	}
+	if err := registerPlugins(conf.Plugins); err != nil {
+		return nil, err
+	}
+	registerConfigured(conf)
+
+	app := &App{
+		Config:  conf,
+		History: history.NewFileHistoryRepository(conf.Stores.History),
+	}
+
+	// The built-in shortener and tracking links are served by gocial itself
+	shortenerConf := conf.Shortener
+	if shortenerConf.Builtin.BaseURL == "" {
+		shortenerConf.Builtin.BaseURL = conf.Server.BaseURL
+	}
+	s, err := shortener.New(shortenerConf)
+	if err != nil {
+		return nil, err
+	}
+	app.Shortener = s
+
+	if conf.Tracking.Enabled {
+		baseURL := conf.Tracking.BaseURL
+		if baseURL == "" {
+			baseURL = conf.Server.BaseURL
+		}
+		app.AnalyticsService = analytics.NewService(analytics.ServiceConfig{
+			Repo:    analytics.NewFileAnalyticsRepository(conf.Tracking.StorePath, conf.Tracking.Retention),
+			BaseURL: baseURL,
+		})
+	}
+
+	app.ShareService = share.NewShareService(share.ServiceConfig{
+		Repositories: provider.NewRepositoryFactory(conf.Providers),
+		UTM:          conf.UTM,
+		Shortener:    app.Shortener,
+		History:      app.History,
+		Analytics:    app.AnalyticsService,
+	})
+	app.MetricsService = metrics.NewService(metrics.ServiceConfig{
+		Repo:         metrics.NewFileMetricsRepository(conf.Stores.Metrics),
+		History:      app.History,
+		ShareService: app.ShareService,
+	})
+	app.CrossPostService = crosspost.NewService(crosspost.ServiceConfig{
+		Repositories: provider.NewCrossPostFactory(),
+		Records:      crosspost.NewFileRecordRepository(conf.Stores.CrossPosts),
+	})
+	return app, nil
+}
+
+// Check returns all problems of the configuration
+func (a *App) Check() []config.Problem {
+	return a.Config.Check(provider.Names())
+}
+
+// Validate logs configuration warnings and returns an error if the
+// configuration has any other problems
+func (a *App) Validate() error {
+	if err := a.Config.Validate(provider.Names()); err != nil {
+		return err
+	}
+	for _, p := range a.Check() {
+		log.Println(p)
+	}
+	return nil
+}
+
+// OAuthConfigs returns the OAuth configs of all configured providers
+func (a *App) OAuthConfigs() []oauth.OAuthConfig {
+	confs := make([]oauth.OAuthConfig, 0)
+	for _, p := range a.Config.Providers {
+		confs = append(confs, oauth.OAuthConfig{
+			ProviderName: p.Name,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			Scopes:       p.Scopes,
+			CallbackURL:  p.CallbackURLFor(a.Config.Server.BaseURL),
+		})
+	}
+	return confs
+}
+
+// OAuthConfig returns the OAuth config of a single provider
+func (a *App) OAuthConfig(provider string) (oauth.OAuthConfig, error) {
+	for _, c := range a.OAuthConfigs() {
+		if c.ProviderName == provider {
+			return c, nil
+		}
+	}
+	return oauth.OAuthConfig{}, fmt.Errorf("Unknown provider: %s", provider)
+}
+
+// SessionConfig returns the settings of the OAuth session cookie
+func (a *App) SessionConfig() oauth.SessionConfig {
+	return oauth.SessionConfig{
+		Key:    a.Config.Cookie.SessionKey,
+		MaxAge: a.Config.Cookie.SessionMaxAge,
+		Secure: a.Config.Cookie.Secure,
+	}
+}
+
+// Identities returns the local identity store
+func (a *App) Identities() (provider.IdentityRepository, error) {
+	idRepo := identity.NewFileIdentityRepo(a.Config.Stores.Identities)
+	if err := idRepo.Load(); err != nil {
+		return provider.IdentityRepository{}, fmt.Errorf("Couldn't load identities: %s", err)
+	}
+	return provider.IdentityRepository{Repository: idRepo, Local: true}, nil
+}
+
+// WatchService returns a feed watcher sharing with the given identities
+func (a *App) WatchService(conf config.WatchConfig, ids identity.Repository) watch.Service {
+	return watch.NewService(watch.ServiceConfig{
+		Repo:         watch.NewFileStateRepository(a.Config.Stores.WatchState),
+		ShareService: a.ShareService,
+		Identities:   ids,
+		Feeds:        conf.Feeds,
+		Interval:     conf.Interval,
+		DryRun:       conf.DryRun,
+	})
+}
+
+// HTTPServerConfig validates the configuration and returns the
+// configuration of the web server. Identities are stored in cookies.
+// Configured feeds are watched in the background using the local identity
+// store.
+func (a *App) HTTPServerConfig() (server.HTTPServerConfig, error) {
+	conf := a.Config
+	if err := a.Validate(); err != nil {
+		return server.HTTPServerConfig{}, err
+	}
+
+	providerIndex := oauth.SetupAuthProviders(a.OAuthConfigs())
+	oauthService := oauth.NewService(oauth.ServiceConfig{
+		Repo:          oauth.NewGothRepository(providerIndex, conf.JWT.Secret, a.SessionConfig()),
+		ProviderIndex: providerIndex,
+	})
+	cookieIdentityRepo := identity.NewCookieIdentityRepository(&identity.CookieIdentityOptions{
+		BaseCookieName:  conf.Cookie.Name,
+		TokenSigningKey: conf.JWT.Secret,
+		TokenExpiration: conf.JWT.Expiration,
+		Expiration:      conf.Cookie.Expiration,
+	})
+
+	webServerConf := server.HTTPServerConfig{
+		ListenAddr:      conf.Server.ListenAddr,
+		TokenSigningKey: conf.JWT.Secret,
+		TokenExpiration: conf.JWT.Expiration,
+		ShareService:    a.ShareService,
+		OAuthService:    oauthService,
+		IdentityService: provider.IdentityRepository{
+			Repository: cookieIdentityRepo,
+			Operators:  conf.Server.Operators,
+		},
+		ProviderIndex:    &providerIndex,
+		Shortener:        a.Shortener,
+		AnalyticsService: a.AnalyticsService,
+		MetricsService:   a.MetricsService,
+	}
+
+	if conf.ShareFeed.Enabled {
+		webServerConf.FeedService = feed.NewService(feed.ServiceConfig{
+			History:     a.History,
+			Title:       conf.ShareFeed.Title,
+			Description: conf.ShareFeed.Description,
+			BaseURL:     conf.Server.BaseURL,
+			Limit:       conf.ShareFeed.Limit,
+		})
+	}
+
+	if len(conf.Watch.Feeds) > 0 {
+		idRepo, err := a.Identities()
+		if err != nil {
+			return server.HTTPServerConfig{}, err
+		}
+		webServerConf.WatchService = a.WatchService(conf.Watch, idRepo)
+	}
+	return webServerConf, nil
+}
+
+// resolveSecrets resolves all secret references in conf
+func resolveSecrets(conf *config.Config) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resolvers := secret.Resolvers{
+		"env":  secret.Env,
+		"file": secret.File,
+		"exec": secret.Exec{Timeout: 10 * time.Second},
+	}
+
+	// The Vault token itself may be a reference
+	vaultToken, err := resolvers.Resolve(ctx, conf.Secrets.Vault.Token)
+	if err != nil {
+		return fmt.Errorf("secrets.vault.token: %s", err)
+	}
+
+	resolvers["awssm"] = secret.AWSSecretsManager{
+		Region:   conf.Secrets.AWSSM.Region,
+		Endpoint: conf.Secrets.AWSSM.Endpoint,
+	}
+	if conf.Secrets.AWSSM.Local != "" {
+		resolvers["awssm"] = secret.LocalStore{Path: conf.Secrets.AWSSM.Local}
+	}
+	resolvers["vault"] = secret.Vault{
+		Address: conf.Secrets.Vault.Address,
+		Token:   vaultToken,
+	}
+	if conf.Secrets.Vault.Local != "" {
+		resolvers["vault"] = secret.LocalStore{Path: conf.Secrets.Vault.Local}
+	}
+
+	return conf.ResolveSecrets(func(value string) (string, error) {
+		return resolvers.Resolve(ctx, value)
+	})
+}
+
+// registerPlugins registers all plugins found in the plugin directory.
+// Plugins can't replace built-in providers.
+func registerPlugins(conf config.PluginsConfig) error {
+	if conf.Dir == "" {
+		return nil
+	}
+
+	dir := conf.Dir
+	if strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("Couldn't expand %s: %s", dir, err)
+		}
+		dir = filepath.Join(home, dir[2:])
+	}
+
+	plugins, err := plugin.Discover(context.Background(), dir, conf.Timeout, conf.Settings)
+	if err != nil {
+		return err
+	}
+	for _, p := range plugins {
+		if _, ok := provider.Get(p.Info.Name); ok {
+			log.Printf("Skipping plugin %s: provider already registered\n", p.Path)
+			continue
+		}
+		provider.Register(p.Provider())
+	}
+	return nil
+}
+
+// registerConfigured registers the providers built from the configuration:
+// Telegram, Matrix, Mastodon, Reddit, Nostr and all named targets (webhooks, Slack, Discord
+// and email). They can't replace other providers.
+func registerConfigured(conf *config.Config) {
+	configured := []provider.Provider{
+		telegram.Provider(conf.Telegram),
+		matrix.Provider(conf.Matrix),
+		mastodon.Provider(conf.Mastodon),
+		reddit.Provider(conf.Reddit),
+		nostr.Provider(conf.Nostr),
+	}
+	for _, w := range conf.Webhooks {
+		configured = append(configured, webhook.Provider(w))
+	}
+	for _, s := range conf.Slack {
+		configured = append(configured, slack.Provider(s))
+	}
+	for _, d := range conf.Discord {
+		configured = append(configured, discord.Provider(d))
+	}
+	for _, e := range conf.Email {
+		configured = append(configured, email.Provider(e))
+	}
+
+	for _, p := range configured {
+		if _, ok := provider.Get(p.Name); ok {
+			log.Printf("Skipping %s: provider already registered\n", p.Name)
+			continue
+		}
+		provider.Register(p)
+	}
+}
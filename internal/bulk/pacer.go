@@ -0,0 +1,55 @@
+package bulk
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Pacer makes sure there is a minimum delay between two posts
+// to the same provider
+type Pacer struct {
+	delay    time.Duration
+	mu       sync.Mutex
+	lastPost map[string]time.Time
+}
+
+func NewPacer(delay time.Duration) *Pacer {
+	return &Pacer{
+		delay:    delay,
+		lastPost: make(map[string]time.Time),
+	}
+}
+
+// Wait blocks until the next post to provider is allowed
+func (p *Pacer) Wait(ctx context.Context, provider string) error {
+	p.mu.Lock()
+	next := p.lastPost[provider].Add(p.delay)
+	p.mu.Unlock()
+
+	if err := WaitUntil(ctx, next); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.lastPost[provider] = time.Now()
+	p.mu.Unlock()
+	return nil
+}
+
+// WaitUntil blocks until t or until ctx is cancelled
+func WaitUntil(ctx context.Context, t time.Time) error {
+	d := time.Until(t)
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,169 @@
+package bulk
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/go-playground/validator/v10"
+)
+
+// RowError is a validation error of a single row
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Err)
+}
+
+// ProviderCheck returns an error if nothing can be shared to the named
+// provider, e.g. because it is unknown or there is no identity for it
+type ProviderCheck func(name string) error
+
+// jsonRow is a single line of a JSONL import file
+type jsonRow struct {
+	entity.ArticleShare
+	Schedule string `json:"schedule"`
+}
+
+// ParseCSV reads shares from a CSV file. The first line is a header
+// containing (in any order) url, title, comment, providers and optionally
+// schedule, subreddits and tags. Providers, subreddits and tags are
+// separated by ";" or ",". If check is set, every provider is checked.
+func ParseCSV(r io.Reader, check ProviderCheck) ([]entity.ScheduledShare, []RowError, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Couldn't read CSV header: %s", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"url", "title", "comment", "providers"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("Missing CSV column: %s", required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	shares := make([]entity.ScheduledShare, 0)
+	rowErrors := make([]RowError, 0)
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: row, Err: err})
+			continue
+		}
+
+		article := entity.ArticleShare{
+			URL:        field(record, "url"),
+			Title:      field(record, "title"),
+			Comment:    field(record, "comment"),
+			Providers:  field(record, "providers"),
+			Subreddits: strings.ReplaceAll(field(record, "subreddits"), ";", ","),
+			Tags:       strings.ReplaceAll(field(record, "tags"), ";", ","),
+		}
+		share, err := newScheduledShare(row, article, field(record, "schedule"), check)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: row, Err: err})
+			continue
+		}
+		shares = append(shares, share)
+	}
+	return shares, rowErrors, nil
+}
+
+// ParseJSONL reads shares from a file containing one JSON object per line.
+// Objects have the same fields as the share API plus an optional schedule.
+// If check is set, every provider is checked.
+func ParseJSONL(r io.Reader, check ProviderCheck) ([]entity.ScheduledShare, []RowError, error) {
+	scanner := bufio.NewScanner(r)
+
+	shares := make([]entity.ScheduledShare, 0)
+	rowErrors := make([]RowError, 0)
+	for row := 1; scanner.Scan(); row++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var jr jsonRow
+		if err := json.Unmarshal([]byte(line), &jr); err != nil {
+			rowErrors = append(rowErrors, RowError{Row: row, Err: err})
+			continue
+		}
+		share, err := newScheduledShare(row, jr.ArticleShare, jr.Schedule, check)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: row, Err: err})
+			continue
+		}
+		shares = append(shares, share)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("Couldn't read file: %s", err)
+	}
+	return shares, rowErrors, nil
+}
+
+// newScheduledShare validates a single row
+func newScheduledShare(row int, article entity.ArticleShare, schedule string, check ProviderCheck) (entity.ScheduledShare, error) {
+	share := entity.ScheduledShare{Row: row}
+
+	// Normalize list of providers
+	providers := make([]string, 0)
+	for _, p := range strings.FieldsFunc(article.Providers, func(r rune) bool { return r == ',' || r == ';' }) {
+		if p = strings.TrimSpace(p); p != "" {
+			providers = append(providers, p)
+		}
+	}
+	article.Providers = strings.Join(providers, ",")
+
+	if err := validator.New().Struct(article); err != nil {
+		return share, err
+	}
+	if u, err := url.Parse(article.URL); err != nil || u.Scheme == "" || u.Host == "" {
+		return share, fmt.Errorf("Invalid URL: %s", article.URL)
+	}
+	if check != nil {
+		var errs []string
+		for _, p := range providers {
+			if err := check(p); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		if len(errs) > 0 {
+			return share, fmt.Errorf("%s", strings.Join(errs, "; "))
+		}
+	}
+
+	if schedule != "" {
+		t, err := time.Parse(time.RFC3339, schedule)
+		if err != nil {
+			return share, fmt.Errorf("Invalid schedule time (expected RFC3339): %s", schedule)
+		}
+		share.ScheduleAt = &t
+	}
+
+	share.Article = article
+	return share, nil
+}
@@ -1,35 +1,494 @@
 package config
 
 import (
+	_ "embed"
+	"fmt"
 	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+	"time"
 
-	"github.com/dorneanu/gocial/internal/entity"
 	"gopkg.in/yaml.v3"
 )
 
-// Config is a minimal configuration for this utility
+// defaultConfig is applied before the configuration file
+//
+//go:embed default.yaml
+var defaultConfig []byte
+
+// Config is the configuration of all gocial entry points
 type Config struct {
-	ServerPort int                       `yaml:"server_port"`
-	Identities []entity.IdentityProvider `yaml:"identities"`
-	JWT        JWTConfig                 `yaml:"jwt_config"`
+	Server    ServerConfig     `yaml:"server"`
+	Providers []ProviderConfig `yaml:"providers"`
+	Cookie    CookieConfig     `yaml:"cookie"`
+	JWT       JWTConfig        `yaml:"jwt_config"`
+	Stores    StoresConfig     `yaml:"stores"`
+	UTM       UTMConfig        `yaml:"utm"`
+	Shortener ShortenerConfig  `yaml:"shortener"`
+	Tracking  TrackingConfig   `yaml:"tracking"`
+	Watch     WatchConfig      `yaml:"watch"`
+	ShareFeed ShareFeedConfig  `yaml:"share_feed"`
+	ShareFile ShareFileConfig  `yaml:"share_file"`
+	Secrets   SecretsConfig    `yaml:"secrets"`
+	Plugins   PluginsConfig    `yaml:"plugins"`
+	Webhooks  []WebhookConfig  `yaml:"webhooks"`
+	Slack     []SlackConfig    `yaml:"slack"`
+	Discord   []DiscordConfig  `yaml:"discord"`
+	Email     []EmailConfig    `yaml:"email"`
+	Telegram  TelegramConfig   `yaml:"telegram"`
+	Matrix    MatrixConfig     `yaml:"matrix"`
+	Mastodon  MastodonConfig   `yaml:"mastodon"`
+	Reddit    RedditConfig     `yaml:"reddit"`
+	Nostr     NostrConfig      `yaml:"nostr"`
+}
+
+// ServerConfig defines where the HTTP server listens and under which URL
+// it is reachable from the outside (used for OAuth callbacks). Operators are
+// the accounts (<provider>:<user ID>) which may use the targets without an
+// identity (webhooks, Slack, Discord, email and plugins) and the analytics.
+type ServerConfig struct {
+	ListenAddr string   `yaml:"listen_addr"`
+	BaseURL    string   `yaml:"base_url"`
+	Production bool     `yaml:"production"`
+	Operators  []string `yaml:"operators"`
+}
+
+// ProviderConfig holds the OAuth client of a single provider. CallbackURL
+// defaults to <base_url>/auth/callback/<name>.
+type ProviderConfig struct {
+	Name         string   `yaml:"name"`
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	Scopes       []string `yaml:"scopes"`
+	CallbackURL  string   `yaml:"callback_url"`
+}
+
+// CookieConfig configures the OAuth session cookie and the identity cookies
+// (<name>-<provider>) set after a successful login
+type CookieConfig struct {
+	Name          string        `yaml:"name"`
+	SessionKey    string        `yaml:"session_key"`
+	SessionMaxAge time.Duration `yaml:"session_max_age"`
+	Secure        bool          `yaml:"secure"`
+	Expiration    time.Duration `yaml:"expiration"`
 }
 
 type JWTConfig struct {
-	Secret    string `yaml:"secret"`
-	Algorithm string `yaml:"algorithm"`
+	Secret     string        `yaml:"secret"`
+	Algorithm  string        `yaml:"algorithm"`
+	Expiration time.Duration `yaml:"expiration"`
+}
+
+// StoresConfig holds the paths of the file based stores
+type StoresConfig struct {
+	Identities string `yaml:"identities"`
+	History    string `yaml:"history"`
+	Metrics    string `yaml:"metrics"`
+	WatchState string `yaml:"watch_state"`
+	CrossPosts string `yaml:"crossposts"`
+}
+
+// SecretsConfig configures the backends of secret references ("awssm:" and
+// "vault:"). Local points to a JSON file which is used instead of the remote
+// backend (for tests and development).
+type SecretsConfig struct {
+	AWSSM AWSSMConfig `yaml:"awssm"`
+	Vault VaultConfig `yaml:"vault"`
+}
+
+type AWSSMConfig struct {
+	Region   string `yaml:"region"`
+	Endpoint string `yaml:"endpoint"`
+	Local    string `yaml:"local"`
+}
+
+type VaultConfig struct {
+	Address string `yaml:"address"`
+	Token   string `yaml:"token"`
+	Local   string `yaml:"local"`
+}
+
+// PluginsConfig configures external provider plugins. Every executable in
+// Dir is a plugin. Settings are passed to the plugin of the same name.
+type PluginsConfig struct {
+	Dir      string                       `yaml:"dir"`
+	Timeout  time.Duration                `yaml:"timeout"`
+	Settings map[string]map[string]string `yaml:"settings"`
+}
+
+// WebhookConfig describes a named webhook target which can be selected like
+// any other provider. Format is "json" (default) or "form". JSON payloads
+// are rendered from the Body template, form payloads from the Fields
+// templates. If Secret is set, the payload is signed with HMAC-SHA256.
+type WebhookConfig struct {
+	Name            string            `yaml:"name"`
+	DisplayName     string            `yaml:"display_name"`
+	URL             string            `yaml:"url"`
+	Method          string            `yaml:"method"`
+	Format          string            `yaml:"format"`
+	Body            string            `yaml:"body"`
+	Fields          map[string]string `yaml:"fields"`
+	Headers         map[string]string `yaml:"headers"`
+	Secret          string            `yaml:"secret"`
+	SignatureHeader string            `yaml:"signature_header"`
+	// ExpectedStatus lists the accepted status codes (default: any 2xx)
+	ExpectedStatus []int         `yaml:"expected_status"`
+	Timeout        time.Duration `yaml:"timeout"`
+}
+
+// SlackConfig describes a named Slack incoming webhook. Username, IconEmoji
+// and Channel override the defaults of the webhook.
+type SlackConfig struct {
+	Name        string `yaml:"name"`
+	DisplayName string `yaml:"display_name"`
+	WebhookURL  string `yaml:"webhook_url"`
+	Username    string `yaml:"username"`
+	IconEmoji   string `yaml:"icon_emoji"`
+	Channel     string `yaml:"channel"`
+}
+
+// DiscordConfig describes a named Discord webhook. If FetchThumbnail is
+// set, the og:image of the shared article is used as thumbnail of the
+// embed, otherwise ThumbnailURL. Articles are only fetched from public
+// addresses and not for previews.
+type DiscordConfig struct {
+	Name           string `yaml:"name"`
+	DisplayName    string `yaml:"display_name"`
+	WebhookURL     string `yaml:"webhook_url"`
+	Username       string `yaml:"username"`
+	AvatarURL      string `yaml:"avatar_url"`
+	Color          int    `yaml:"color"`
+	ThumbnailURL   string `yaml:"thumbnail_url"`
+	FetchThumbnail bool   `yaml:"fetch_thumbnail"`
+}
+
+// EmailConfig describes a named email target sending shared articles via
+// SMTP. Every recipient gets a message of its own, so To can hold a list of
+// subscribers or a single mailing-list address. Subject, Text and HTML are
+// templates of the message.
+type EmailConfig struct {
+	Name        string `yaml:"name"`
+	DisplayName string `yaml:"display_name"`
+	Host        string `yaml:"host"`
+	// Port defaults to 587 (starttls), 465 (tls) or 25 (none)
+	Port int `yaml:"port"`
+	// TLS is starttls (default), tls (implicit TLS) or none (local sinks)
+	TLS      string   `yaml:"tls"`
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+	ReplyTo  string   `yaml:"reply_to"`
+	// BounceAddress receives bounces (envelope sender). Defaults to From.
+	BounceAddress string `yaml:"bounce_address"`
+	// ListUnsubscribe is a mailto: or https URL sent as List-Unsubscribe
+	ListUnsubscribe string `yaml:"list_unsubscribe"`
+	Subject         string `yaml:"subject"`
+	Text            string `yaml:"text"`
+	HTML            string `yaml:"html"`
+	// BatchSize is the number of messages sent over one connection
+	// (default: 50). BatchDelay is the pause between two batches.
+	BatchSize  int           `yaml:"batch_size"`
+	BatchDelay time.Duration `yaml:"batch_delay"`
+	Timeout    time.Duration `yaml:"timeout"`
+}
+
+// TelegramConfig configures the messages sent by the Telegram provider. The
+// bot token and chat are kept in the identity store.
+type TelegramConfig struct {
+	// DisableLinkPreview hides the preview of the shared URL
+	DisableLinkPreview bool `yaml:"disable_link_preview"`
+	// Silent sends messages without notification
+	Silent bool `yaml:"silent"`
+}
+
+// MatrixConfig configures the messages sent by the Matrix provider. The
+// homeserver, access token and room are kept in the identity store.
+type MatrixConfig struct {
+	// MsgType is "m.text" or "m.notice" (for bots)
+	MsgType string `yaml:"msgtype"`
+}
+
+// MastodonConfig configures the statuses sent by the Mastodon provider. The
+// instance and access token are kept in the identity store.
+type MastodonConfig struct {
+	// Visibility is public, unlisted, private or direct
+	Visibility string `yaml:"visibility"`
+	// MaxCharacters is the status limit of the instance (URLs count as 23)
+	MaxCharacters int `yaml:"max_characters"`
+}
+
+// RedditConfig configures link submissions to Reddit. Subreddits are used
+// for shares which don't name any.
+type RedditConfig struct {
+	Subreddits []string `yaml:"subreddits"`
+	// SendReplies sends replies to submissions to the user's inbox
+	SendReplies bool `yaml:"send_replies"`
+}
+
+// NostrConfig lists the relays notes are published to. The private key is
+// kept in the identity store.
+type NostrConfig struct {
+	Relays []string `yaml:"relays"`
+	// Timeout limits the time to wait for the OK message of a relay
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// ShareFileConfig configures the share-file command
+type ShareFileConfig struct {
+	// BaseURL is the URL the slug of a post is appended to
+	BaseURL string `yaml:"base_url"`
+}
+
+// UTMConfig defines which UTM parameters are appended to shared URLs.
+// Only URLs pointing to one of the configured domains (or their subdomains)
+// are tagged.
+type UTMConfig struct {
+	Domains map[string]UTMDomainConfig `yaml:"domains"`
+}
+
+// UTMDomainConfig holds the UTM parameters for a single domain. Providers
+// may override the defaults; utm_source defaults to the provider name.
+type UTMDomainConfig struct {
+	Medium    string               `yaml:"medium"`
+	Campaign  string               `yaml:"campaign"`
+	Providers map[string]UTMParams `yaml:"providers"`
+}
+
+// UTMParams are the actual utm_* query parameters
+type UTMParams struct {
+	Source   string `yaml:"source"`
+	Medium   string `yaml:"medium"`
+	Campaign string `yaml:"campaign"`
+}
+
+// ShortenerConfig selects the URL shortener used for shared links.
+// Type is one of "rest", "yourls" or "builtin" (empty disables shortening).
+type ShortenerConfig struct {
+	Type    string                 `yaml:"type"`
+	REST    RESTShortenerConfig    `yaml:"rest"`
+	Yourls  YourlsShortenerConfig  `yaml:"yourls"`
+	Builtin BuiltinShortenerConfig `yaml:"builtin"`
+}
+
+// RESTShortenerConfig describes the request sent to a generic shortening API.
+// URL and Body are templates; {{.URL}} and {{.EscapedURL}} hold the long URL.
+type RESTShortenerConfig struct {
+	Method        string            `yaml:"method"`
+	URL           string            `yaml:"url"`
+	Body          string            `yaml:"body"`
+	Headers       map[string]string `yaml:"headers"`
+	ResponseField string            `yaml:"response_field"`
 }
 
+type YourlsShortenerConfig struct {
+	URL       string `yaml:"url"`
+	Signature string `yaml:"signature"`
+}
+
+// BuiltinShortenerConfig configures the shortener served at /s/:code
+type BuiltinShortenerConfig struct {
+	BaseURL   string `yaml:"base_url"`
+	StorePath string `yaml:"store_path"`
+}
+
+// TrackingConfig enables tracking links (served at /t/:code) for every share.
+// Clicks are counted per day and kept for Retention (0 keeps them forever).
+type TrackingConfig struct {
+	Enabled   bool          `yaml:"enabled"`
+	BaseURL   string        `yaml:"base_url"`
+	StorePath string        `yaml:"store_path"`
+	Retention time.Duration `yaml:"retention"`
+}
+
+// ShareFeedConfig publishes the share history as Atom, RSS and JSON feed
+// (served at /feed.atom, /feed.rss and /feed.json). Limit is the maximum
+// number of items per feed.
+type ShareFeedConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	Limit       int    `yaml:"limit"`
+}
+
+// WatchConfig defines which feeds are watched for new entries
+type WatchConfig struct {
+	Interval time.Duration `yaml:"interval"`
+	DryRun   bool          `yaml:"dry_run"`
+	Feeds    []FeedConfig  `yaml:"feeds"`
+}
+
+// FeedConfig describes a single watched feed. Template is used to build the
+// comment from an entry (e.g. "New post: {{.Title}}"). New entries are
+// shared Delay after they were found (immediately if 0).
+type FeedConfig struct {
+	URL       string        `yaml:"url"`
+	Providers []string      `yaml:"providers"`
+	Template  string        `yaml:"template"`
+	Delay     time.Duration `yaml:"delay"`
+}
+
+// Provider returns the config of the named provider
+func (c *Config) Provider(name string) (ProviderConfig, bool) {
+	for _, p := range c.Providers {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return ProviderConfig{}, false
+}
+
+// CallbackURLFor returns the OAuth callback URL of the provider when the
+// server is reachable at baseURL
+func (p ProviderConfig) CallbackURLFor(baseURL string) string {
+	if p.CallbackURL != "" {
+		return p.CallbackURL
+	}
+	return fmt.Sprintf("%s/auth/callback/%s", strings.TrimSuffix(baseURL, "/"), p.Name)
+}
+
+// secretField points to a setting holding a secret
+type secretField struct {
+	field string
+	value *string
+}
+
+// ResolveSecrets replaces all settings holding secrets by the result of
+// resolve (e.g. "env:NAME" by the value of the environment variable)
+func (c *Config) ResolveSecrets(resolve func(string) (string, error)) error {
+	secrets := []secretField{
+		{"cookie.session_key", &c.Cookie.SessionKey},
+		{"jwt_config.secret", &c.JWT.Secret},
+		{"shortener.yourls.signature", &c.Shortener.Yourls.Signature},
+	}
+	for i := range c.Providers {
+		p := &c.Providers[i]
+		secrets = append(secrets,
+			secretField{fmt.Sprintf("providers[%d].client_id", i), &p.ClientID},
+			secretField{fmt.Sprintf("providers[%d].client_secret", i), &p.ClientSecret},
+		)
+	}
+
+	// Webhook URLs usually contain a token
+	for i := range c.Webhooks {
+		w := &c.Webhooks[i]
+		secrets = append(secrets,
+			secretField{fmt.Sprintf("webhooks[%d].url", i), &w.URL},
+			secretField{fmt.Sprintf("webhooks[%d].secret", i), &w.Secret},
+		)
+	}
+	for i := range c.Slack {
+		secrets = append(secrets, secretField{fmt.Sprintf("slack[%d].webhook_url", i), &c.Slack[i].WebhookURL})
+	}
+	for i := range c.Discord {
+		secrets = append(secrets, secretField{fmt.Sprintf("discord[%d].webhook_url", i), &c.Discord[i].WebhookURL})
+	}
+	for i := range c.Email {
+		secrets = append(secrets, secretField{fmt.Sprintf("email[%d].password", i), &c.Email[i].Password})
+	}
+
+	for _, s := range secrets {
+		v, err := resolve(*s.value)
+		if err != nil {
+			return fmt.Errorf("%s: %s", s.field, err)
+		}
+		*s.value = v
+	}
+
+	// Plugin settings may carry credentials
+	for plugin, settings := range c.Plugins.Settings {
+		for name, value := range settings {
+			v, err := resolve(value)
+			if err != nil {
+				return fmt.Errorf("plugins.settings.%s.%s: %s", plugin, name, err)
+			}
+			settings[name] = v
+		}
+	}
+
+	// Webhook headers usually carry credentials
+	for i := range c.Webhooks {
+		w := &c.Webhooks[i]
+		for name, value := range w.Headers {
+			v, err := resolve(value)
+			if err != nil {
+				return fmt.Errorf("webhooks[%d].headers.%s: %s", i, name, err)
+			}
+			w.Headers[name] = v
+		}
+	}
+
+	// Headers of the REST shortener usually carry API tokens
+	for name, value := range c.Shortener.REST.Headers {
+		v, err := resolve(value)
+		if err != nil {
+			return fmt.Errorf("shortener.rest.headers.%s: %s", name, err)
+		}
+		c.Shortener.REST.Headers[name] = v
+	}
+	return nil
+}
+
+// Load reads the configuration from a YAML file. Settings missing in the
+// file are taken from the defaults. An empty file name only loads the
+// defaults.
 func Load(file string) (*Config, error) {
-	c := Config{}
+	if file == "" {
+		return Parse(nil)
+	}
 
-	// Load config from YAML file
-	bytes, err := ioutil.ReadFile(file)
+	data, err := ioutil.ReadFile(file)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("Couldn't read config: %s", err)
 	}
+	return Parse(data)
+}
+
+// Parse parses a YAML configuration on top of the defaults. References to
+// environment variables (${NAME} or ${NAME:-default}) are replaced in all
+// values.
+func Parse(data []byte) (*Config, error) {
+	c := Config{}
+	for _, d := range [][]byte{defaultConfig, data} {
+		if len(d) == 0 {
+			continue
+		}
 
-	if err = yaml.Unmarshal(bytes, &c); err != nil {
-		return nil, err
+		var doc yaml.Node
+		if err := yaml.Unmarshal(d, &doc); err != nil {
+			return nil, fmt.Errorf("Couldn't parse config: %s", err)
+		}
+		interpolate(&doc)
+		if err := doc.Decode(&c); err != nil {
+			return nil, fmt.Errorf("Couldn't parse config: %s", err)
+		}
 	}
 	return &c, nil
 }
+
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// interpolate replaces environment variable references in all scalar values
+func interpolate(n *yaml.Node) {
+	if n.Kind == yaml.ScalarNode {
+		if !envRef.MatchString(n.Value) {
+			return
+		}
+		n.Value = envRef.ReplaceAllStringFunc(n.Value, func(ref string) string {
+			m := envRef.FindStringSubmatch(ref)
+			if v, ok := os.LookupEnv(m[1]); ok && v != "" {
+				return v
+			}
+			return m[3]
+		})
+		// Unquoted values are resolved again (e.g. as bool or duration)
+		if n.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) == 0 {
+			n.Tag = ""
+		}
+		return
+	}
+	for _, c := range n.Content {
+		interpolate(c)
+	}
+}
@@ -0,0 +1,464 @@
+package config
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// minSecretLength is the minimum length of signing keys which are not
+// reported as weak
+const minSecretLength = 32
+
+// validTargetName matches names which can be used as provider names
+var validTargetName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// weakSecrets are well known values which must never be used as keys
+var weakSecrets = map[string]bool{
+	"secret key":         true,
+	"Secret-session-key": true,
+	"secret":             true,
+	"changeme":           true,
+}
+
+// Problem describes a single configuration problem. Warnings don't prevent
+// gocial from starting.
+type Problem struct {
+	Field   string
+	Message string
+	Warning bool
+}
+
+func (p Problem) String() string {
+	level := "error"
+	if p.Warning {
+		level = "warning"
+	}
+	return fmt.Sprintf("%s: %s: %s", level, p.Field, p.Message)
+}
+
+// ValidationError holds all problems found by Validate
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, 0)
+	for _, p := range e.Problems {
+		msgs = append(msgs, p.String())
+	}
+	return fmt.Sprintf("Invalid configuration:\n  %s", strings.Join(msgs, "\n  "))
+}
+
+// checker collects problems
+type checker struct {
+	problems   []Problem
+	production bool
+}
+
+func (c *checker) errorf(field, format string, args ...interface{}) {
+	c.problems = append(c.problems, Problem{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) warnf(field, format string, args ...interface{}) {
+	c.problems = append(c.problems, Problem{Field: field, Message: fmt.Sprintf(format, args...), Warning: true})
+}
+
+// productionf reports an error in production and a warning otherwise
+func (c *checker) productionf(field, format string, args ...interface{}) {
+	if c.production {
+		c.errorf(field, format, args...)
+	} else {
+		c.warnf(field, format, args...)
+	}
+}
+
+// Check returns all problems of the configuration. knownProviders are the
+// names of the providers gocial supports.
+func (c *Config) Check(knownProviders []string) []Problem {
+	ch := &checker{production: c.Server.Production}
+
+	// Server
+	if c.Server.ListenAddr == "" {
+		ch.errorf("server.listen_addr", "must be set")
+	}
+	baseURL := ch.checkURL("server.base_url", c.Server.BaseURL, true)
+	if ch.production && baseURL != nil && baseURL.Scheme != "https" {
+		ch.errorf("server.base_url", "must use https in production (got %s)", c.Server.BaseURL)
+	}
+
+	// Providers
+	known := make(map[string]bool)
+	for _, p := range knownProviders {
+		known[p] = true
+	}
+	for i, op := range c.Server.Operators {
+		field := fmt.Sprintf("server.operators[%d]", i)
+		parts := strings.SplitN(op, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			ch.errorf(field, "must be <provider>:<user ID> (got %q)", op)
+		} else if !known[parts[0]] {
+			ch.errorf(field, "unknown provider %q", parts[0])
+		}
+	}
+	seen := make(map[string]bool)
+	schemes := make(map[string][]string)
+	for i, p := range c.Providers {
+		field := fmt.Sprintf("providers[%d]", i)
+		if p.Name == "" {
+			ch.errorf(field+".name", "must be set")
+			continue
+		}
+		field = fmt.Sprintf("providers[%d] (%s)", i, p.Name)
+		if !known[p.Name] {
+			ch.errorf(field, "unknown provider (supported: %s)", strings.Join(knownProviders, ", "))
+		}
+		if seen[p.Name] {
+			ch.errorf(field, "configured more than once")
+		}
+		seen[p.Name] = true
+
+		if p.ClientID == "" {
+			ch.errorf(field+".client_id", "is empty (environment variable not set?)")
+		}
+		if p.ClientSecret == "" {
+			ch.errorf(field+".client_secret", "is empty (environment variable not set?)")
+		}
+
+		callback := p.CallbackURLFor(c.Server.BaseURL)
+		if u := ch.checkURL(field+".callback_url", callback, true); u != nil {
+			schemes[u.Scheme] = append(schemes[u.Scheme], p.Name)
+			if ch.production && u.Scheme != "https" {
+				ch.errorf(field+".callback_url", "must use https in production (got %s)", callback)
+			}
+			if baseURL != nil && u.Host != baseURL.Host {
+				ch.warnf(field+".callback_url", "host %s differs from server.base_url", u.Host)
+			}
+		}
+	}
+	if len(schemes) > 1 {
+		ch.errorf("providers", "callback URLs mix http (%s) and https (%s)",
+			strings.Join(schemes["http"], ", "), strings.Join(schemes["https"], ", "))
+	}
+
+	// Cookies and JWT
+	if c.Cookie.Name == "" {
+		ch.errorf("cookie.name", "must be set")
+	}
+	ch.checkSecret("cookie.session_key", c.Cookie.SessionKey)
+	if ch.production && !c.Cookie.Secure {
+		ch.errorf("cookie.secure", "must be enabled in production")
+	}
+	if c.Cookie.SessionMaxAge <= 0 {
+		ch.errorf("cookie.session_max_age", "must be positive")
+	}
+	ch.checkSecret("jwt_config.secret", c.JWT.Secret)
+	if c.JWT.Algorithm != "HS256" {
+		ch.errorf("jwt_config.algorithm", "unsupported algorithm %q (supported: HS256)", c.JWT.Algorithm)
+	}
+	if c.JWT.Expiration <= 0 {
+		ch.errorf("jwt_config.expiration", "must be positive")
+	}
+
+	// Stores
+	stores := []struct{ field, path string }{
+		{"stores.identities", c.Stores.Identities},
+		{"stores.history", c.Stores.History},
+		{"stores.metrics", c.Stores.Metrics},
+		{"stores.watch_state", c.Stores.WatchState},
+		{"stores.crossposts", c.Stores.CrossPosts},
+	}
+	for _, s := range stores {
+		if s.path == "" {
+			ch.errorf(s.field, "must be set")
+		}
+	}
+
+	// Shortener and tracking
+	switch c.Shortener.Type {
+	case "":
+	case "rest":
+		ch.checkURL("shortener.rest.url", c.Shortener.REST.URL, false)
+	case "yourls":
+		ch.checkURL("shortener.yourls.url", c.Shortener.Yourls.URL, true)
+		if c.Shortener.Yourls.Signature == "" {
+			ch.errorf("shortener.yourls.signature", "must be set")
+		}
+	case "builtin":
+		if c.Shortener.Builtin.StorePath == "" {
+			ch.errorf("shortener.builtin.store_path", "must be set")
+		}
+	default:
+		ch.errorf("shortener.type", "unknown shortener %q (supported: rest, yourls, builtin)", c.Shortener.Type)
+	}
+	if c.Tracking.Enabled && c.Tracking.StorePath == "" {
+		ch.errorf("tracking.store_path", "must be set")
+	}
+	if c.Tracking.Retention < 0 {
+		ch.errorf("tracking.retention", "must not be negative")
+	}
+
+	// Share feed
+	if c.ShareFeed.Enabled {
+		if c.ShareFeed.Title == "" {
+			ch.errorf("share_feed.title", "must be set")
+		}
+		if c.ShareFeed.Limit <= 0 {
+			ch.errorf("share_feed.limit", "must be positive")
+		}
+	}
+
+	// Watched feeds
+	if len(c.Watch.Feeds) > 0 && c.Watch.Interval <= 0 {
+		ch.errorf("watch.interval", "must be positive")
+	}
+	for i, f := range c.Watch.Feeds {
+		field := fmt.Sprintf("watch.feeds[%d]", i)
+		ch.checkURL(field+".url", f.URL, true)
+		if len(f.Providers) == 0 {
+			ch.errorf(field+".providers", "must not be empty")
+		}
+		for _, p := range f.Providers {
+			if !known[p] {
+				ch.errorf(field+".providers", "unknown provider %q", p)
+			}
+		}
+		if f.Template != "" {
+			if _, err := template.New("").Parse(f.Template); err != nil {
+				ch.errorf(field+".template", "%s", err)
+			}
+		}
+		if f.Delay < 0 {
+			ch.errorf(field+".delay", "must not be negative")
+		}
+	}
+
+	if c.Plugins.Dir != "" && c.Plugins.Timeout <= 0 {
+		ch.errorf("plugins.timeout", "must be positive")
+	}
+	for name := range c.Plugins.Settings {
+		if !known[name] {
+			ch.warnf("plugins.settings."+name, "no plugin with this name was found")
+		}
+	}
+
+	// Webhooks
+	for i, w := range c.Webhooks {
+		field := ch.checkTargetName(fmt.Sprintf("webhooks[%d]", i), w.Name, seen)
+		ch.checkURL(field+".url", w.URL, true)
+		switch w.Format {
+		case "", "json":
+			if len(w.Fields) > 0 {
+				ch.warnf(field+".fields", "only used with format form")
+			}
+		case "form":
+			if w.Body != "" {
+				ch.warnf(field+".body", "only used with format json")
+			}
+		default:
+			ch.errorf(field+".format", "unknown format %q (supported: json, form)", w.Format)
+		}
+		ch.checkWebhookTemplate(field+".body", w.Body)
+		fields := make([]string, 0, len(w.Fields))
+		for name := range w.Fields {
+			fields = append(fields, name)
+		}
+		sort.Strings(fields)
+		for _, name := range fields {
+			ch.checkWebhookTemplate(field+".fields."+name, w.Fields[name])
+		}
+		for _, status := range w.ExpectedStatus {
+			if status < 100 || status > 599 {
+				ch.errorf(field+".expected_status", "invalid status code %d", status)
+			}
+		}
+		if w.Timeout < 0 {
+			ch.errorf(field+".timeout", "must be positive")
+		}
+	}
+
+	// Slack and Discord
+	for i, t := range c.Slack {
+		field := ch.checkTargetName(fmt.Sprintf("slack[%d]", i), t.Name, seen)
+		ch.checkURL(field+".webhook_url", t.WebhookURL, true)
+	}
+	for i, t := range c.Discord {
+		field := ch.checkTargetName(fmt.Sprintf("discord[%d]", i), t.Name, seen)
+		ch.checkURL(field+".webhook_url", t.WebhookURL, true)
+		if t.ThumbnailURL != "" {
+			ch.checkURL(field+".thumbnail_url", t.ThumbnailURL, true)
+		}
+		if t.AvatarURL != "" {
+			ch.checkURL(field+".avatar_url", t.AvatarURL, true)
+		}
+		if t.Color < 0 || t.Color > 0xffffff {
+			ch.errorf(field+".color", "must be an RGB value between 0 and 0xffffff")
+		}
+	}
+
+	// Email
+	for i, e := range c.Email {
+		field := ch.checkTargetName(fmt.Sprintf("email[%d]", i), e.Name, seen)
+		if e.Host == "" {
+			ch.errorf(field+".host", "must be set")
+		}
+		if e.Port < 0 || e.Port > 65535 {
+			ch.errorf(field+".port", "invalid port %d", e.Port)
+		}
+		switch e.TLS {
+		case "", "starttls", "tls":
+		case "none":
+			if e.Username != "" {
+				ch.warnf(field+".tls", "credentials are only sent to localhost without TLS")
+			}
+		default:
+			ch.errorf(field+".tls", "unknown mode %q (supported: starttls, tls, none)", e.TLS)
+		}
+		ch.checkAddress(field+".from", e.From, true)
+		ch.checkAddress(field+".reply_to", e.ReplyTo, false)
+		ch.checkAddress(field+".bounce_address", e.BounceAddress, false)
+		if len(e.To) == 0 {
+			ch.errorf(field+".to", "must list at least one recipient")
+		}
+		for j, to := range e.To {
+			ch.checkAddress(fmt.Sprintf("%s.to[%d]", field, j), to, true)
+		}
+		if e.ListUnsubscribe != "" && !strings.HasPrefix(e.ListUnsubscribe, "mailto:") {
+			ch.checkURL(field+".list_unsubscribe", e.ListUnsubscribe, true)
+		}
+		for _, t := range []struct{ name, text string }{{"subject", e.Subject}, {"text", e.Text}} {
+			if _, err := template.New("").Parse(t.text); err != nil {
+				ch.errorf(field+"."+t.name, "%s", err)
+			}
+		}
+		if _, err := htmltemplate.New("").Parse(e.HTML); err != nil {
+			ch.errorf(field+".html", "%s", err)
+		}
+		if e.BatchSize < 0 {
+			ch.errorf(field+".batch_size", "must be positive")
+		}
+		if e.BatchDelay < 0 {
+			ch.errorf(field+".batch_delay", "must be positive")
+		}
+		if e.Timeout < 0 {
+			ch.errorf(field+".timeout", "must be positive")
+		}
+	}
+
+	if c.Matrix.MsgType != "m.text" && c.Matrix.MsgType != "m.notice" {
+		ch.errorf("matrix.msgtype", "unsupported message type %q (supported: m.text, m.notice)", c.Matrix.MsgType)
+	}
+	switch c.Mastodon.Visibility {
+	case "public", "unlisted", "private", "direct":
+	default:
+		ch.errorf("mastodon.visibility", "unsupported visibility %q (supported: public, unlisted, private, direct)", c.Mastodon.Visibility)
+	}
+	if c.Mastodon.MaxCharacters <= 0 {
+		ch.errorf("mastodon.max_characters", "must be positive")
+	}
+
+	for i, relay := range c.Nostr.Relays {
+		if u, err := url.Parse(relay); err != nil || u.Host == "" || (u.Scheme != "wss" && u.Scheme != "ws") {
+			ch.errorf(fmt.Sprintf("nostr.relays[%d]", i), "not a WebSocket URL (wss://...): %s", relay)
+		}
+	}
+	if c.Nostr.Timeout <= 0 {
+		ch.errorf("nostr.timeout", "must be positive")
+	}
+
+	if c.ShareFile.BaseURL != "" {
+		ch.checkURL("share_file.base_url", c.ShareFile.BaseURL, true)
+	}
+	return ch.problems
+}
+
+// Validate returns a ValidationError if the configuration has any problem
+// which is not a warning
+func (c *Config) Validate(knownProviders []string) error {
+	problems := c.Check(knownProviders)
+	for _, p := range problems {
+		if !p.Warning {
+			return ValidationError{Problems: problems}
+		}
+	}
+	return nil
+}
+
+// checkURL reports field unless value is an absolute http(s) URL. The parsed
+// URL is returned if it is valid.
+func (ch *checker) checkURL(field, value string, required bool) *url.URL {
+	if value == "" {
+		if required {
+			ch.errorf(field, "must be set")
+		}
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		// REST shortener URLs are templates and may not parse
+		if !required && strings.Contains(value, "{{") {
+			return nil
+		}
+		ch.errorf(field, "not an absolute http(s) URL: %s", value)
+		return nil
+	}
+	return u
+}
+
+// checkAddress reports invalid email addresses
+func (ch *checker) checkAddress(field, value string, required bool) {
+	if value == "" {
+		if required {
+			ch.errorf(field, "must be set")
+		}
+		return
+	}
+	if _, err := mail.ParseAddress(value); err != nil {
+		ch.errorf(field, "invalid address %q: %s", value, err)
+	}
+}
+
+// checkTargetName reports invalid and duplicate names of named targets
+// (webhooks, Slack, Discord, email). It returns the field including the name.
+func (ch *checker) checkTargetName(field, name string, seen map[string]bool) string {
+	if !validTargetName.MatchString(name) {
+		ch.errorf(field+".name", "must consist of lowercase letters, digits, - and _ (got %q)", name)
+	}
+	if name != "" {
+		field = fmt.Sprintf("%s (%s)", field, name)
+	}
+	if seen[name] {
+		ch.errorf(field, "name is already used by another provider or target")
+	}
+	seen[name] = true
+	return field
+}
+
+// checkWebhookTemplate reports webhook templates which don't parse
+func (ch *checker) checkWebhookTemplate(field, text string) {
+	// Only the signature of the functions available to webhook templates
+	// matters for parsing
+	funcs := template.FuncMap{
+		"json": func(interface{}) (string, error) { return "", nil },
+	}
+	if _, err := template.New("").Funcs(funcs).Parse(text); err != nil {
+		ch.errorf(field, "%s", err)
+	}
+}
+
+// checkSecret reports missing and weak signing keys
+func (ch *checker) checkSecret(field, value string) {
+	switch {
+	case value == "":
+		ch.errorf(field, "is empty (environment variable not set?)")
+	case weakSecrets[value]:
+		ch.productionf(field, "uses a well-known default value")
+	case len(value) < minSecretLength:
+		ch.productionf(field, "is weak (%d characters, at least %d recommended)", len(value), minSecretLength)
+	}
+}
@@ -0,0 +1,84 @@
+package crosspost
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+// FileRecordRepository implements crosspost.RecordRepository and keeps
+// all records in a single JSON file
+type FileRecordRepository struct {
+	BasePath string
+	mu       sync.Mutex
+}
+
+func NewFileRecordRepository(path string) *FileRecordRepository {
+	return &FileRecordRepository{
+		BasePath: path,
+	}
+}
+
+// Get returns the record of an article on a provider. Articles which
+// weren't cross-posted yet have an empty remote ID.
+func (fr *FileRecordRepository) Get(provider, canonicalURL string) (entity.CrossPost, error) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	records, err := fr.load()
+	if err != nil {
+		return entity.CrossPost{}, err
+	}
+	for _, r := range records {
+		if r.Provider == provider && r.CanonicalURL == canonicalURL {
+			return r, nil
+		}
+	}
+	return entity.CrossPost{Provider: provider, CanonicalURL: canonicalURL}, nil
+}
+
+// Save adds a record or replaces the one of the same article and provider
+func (fr *FileRecordRepository) Save(record entity.CrossPost) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	records, err := fr.load()
+	if err != nil {
+		return err
+	}
+	for i, r := range records {
+		if r.Provider == record.Provider && r.CanonicalURL == record.CanonicalURL {
+			records[i] = record
+			return fr.save(records)
+		}
+	}
+	return fr.save(append(records, record))
+}
+
+func (fr *FileRecordRepository) load() ([]entity.CrossPost, error) {
+	records := make([]entity.CrossPost, 0)
+
+	b, err := ioutil.ReadFile(fr.BasePath)
+	if os.IsNotExist(err) {
+		return records, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("Couldn't open file: %s", err)
+	}
+
+	if err := json.Unmarshal(b, &records); err != nil {
+		return nil, fmt.Errorf("Couldn't unmarshalize data: %s", err)
+	}
+	return records, nil
+}
+
+func (fr *FileRecordRepository) save(records []entity.CrossPost) error {
+	b, err := json.MarshalIndent(records, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fr.BasePath, b, 0600)
+}
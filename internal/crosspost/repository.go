@@ -0,0 +1,31 @@
+// Package crosspost syndicates full articles to blogging platforms (e.g.
+// dev.to) as drafts pointing back to the original article. The remote IDs
+// are recorded so that later runs update the drafts instead of creating new
+// ones.
+package crosspost
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+// ErrNotFound is returned by repositories when a draft to update doesn't
+// exist anymore
+var ErrNotFound = errors.New("Draft not found")
+
+// Repository creates and updates drafts on a platform
+type Repository interface {
+	CreateDraft(context.Context, entity.CrossPostArticle) (entity.ShareResult, error)
+	UpdateDraft(context.Context, string, entity.CrossPostArticle) (entity.ShareResult, error)
+}
+
+// RepositoryFactory returns the repository of an identity's provider
+type RepositoryFactory func(entity.IdentityProvider) (Repository, error)
+
+// RecordRepository stores which articles were cross-posted where
+type RecordRepository interface {
+	Get(provider, canonicalURL string) (entity.CrossPost, error)
+	Save(entity.CrossPost) error
+}
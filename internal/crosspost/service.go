@@ -0,0 +1,77 @@
+package crosspost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+type Service interface {
+	CrossPost(context.Context, entity.CrossPostArticle, entity.IdentityProvider) (entity.CrossPost, error)
+}
+
+// ServiceConfig holds the dependencies of the cross-post service
+type ServiceConfig struct {
+	// Repositories creates the repository of an identity's provider
+	Repositories RepositoryFactory
+	Records      RecordRepository
+}
+
+// crossPostService implements crosspost.Service
+type crossPostService struct {
+	repositories RepositoryFactory
+	records      RecordRepository
+}
+
+func NewService(conf ServiceConfig) Service {
+	return crossPostService{
+		repositories: conf.Repositories,
+		records:      conf.Records,
+	}
+}
+
+// CrossPost creates a draft of the article on the identity's provider. If
+// the article was cross-posted before, the existing draft is updated. Drafts
+// deleted on the provider are created again.
+func (s crossPostService) CrossPost(ctx context.Context, article entity.CrossPostArticle, identity entity.IdentityProvider) (entity.CrossPost, error) {
+	if article.CanonicalURL == "" {
+		return entity.CrossPost{}, fmt.Errorf("Article has no canonical URL")
+	}
+	repo, err := s.repositories(identity)
+	if err != nil {
+		return entity.CrossPost{}, err
+	}
+
+	record, err := s.records.Get(identity.Provider, article.CanonicalURL)
+	if err != nil {
+		return entity.CrossPost{}, err
+	}
+
+	now := time.Now()
+	var result entity.ShareResult
+	if record.RemoteID != "" {
+		result, err = repo.UpdateDraft(ctx, record.RemoteID, article)
+		if errors.Is(err, ErrNotFound) {
+			record.RemoteID = ""
+		}
+	}
+	if record.RemoteID == "" {
+		result, err = repo.CreateDraft(ctx, article)
+		record.CreatedAt = now
+	}
+	if err != nil {
+		return entity.CrossPost{}, err
+	}
+
+	if result.PostID != "" {
+		record.RemoteID = result.PostID
+	}
+	if result.PostURL != "" {
+		record.URL = result.PostURL
+	}
+	record.UpdatedAt = now
+	return record, s.records.Save(record)
+}
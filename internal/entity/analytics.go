@@ -0,0 +1,45 @@
+package entity
+
+import "time"
+
+// TrackingLink redirects to the URL of a single share and provider
+type TrackingLink struct {
+	Code      string    `json:"code"`
+	ShareID   string    `json:"share_id"`
+	Provider  string    `json:"provider"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ClickEvent is an anonymized click on a tracking link. Neither IP addresses
+// nor full referrers or user agents are stored.
+type ClickEvent struct {
+	Code      string    `json:"code"`
+	ShareID   string    `json:"share_id"`
+	Provider  string    `json:"provider"`
+	Referrer  string    `json:"referrer"`
+	UserAgent string    `json:"user_agent"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ClickCount is the number of clicks on a tracking link per day, referrer
+// and device class. Clicks are only stored as counts.
+type ClickCount struct {
+	Day       string `json:"day"`
+	Code      string `json:"code"`
+	ShareID   string `json:"share_id"`
+	Provider  string `json:"provider"`
+	Referrer  string `json:"referrer"`
+	UserAgent string `json:"user_agent"`
+	Count     int    `json:"count"`
+}
+
+// AnalyticsSummary holds aggregated click counts
+type AnalyticsSummary struct {
+	TotalClicks int            `json:"total_clicks"`
+	ByProvider  map[string]int `json:"by_provider"`
+	ByShare     map[string]int `json:"by_share"`
+	ByReferrer  map[string]int `json:"by_referrer"`
+	ByUserAgent map[string]int `json:"by_user_agent"`
+	ByDay       map[string]int `json:"by_day"`
+}
@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// ScheduledShare is an article share read from a bulk import file
+type ScheduledShare struct {
+	// Row is the line (CSV/JSONL) the share was read from
+	Row     int
+	Article ArticleShare
+	// ScheduleAt is the time the article should be shared at (nil means now)
+	ScheduleAt *time.Time
+}
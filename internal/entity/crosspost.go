@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// CrossPostArticle is a full article syndicated to a blogging platform
+type CrossPostArticle struct {
+	Title        string   `json:"title"`
+	Description  string   `json:"description"`
+	CanonicalURL string   `json:"canonical_url"`
+	Tags         []string `json:"tags"`
+	CoverImage   string   `json:"cover_image"`
+	// Body is Markdown without front matter
+	Body string `json:"body"`
+}
+
+// CrossPost links an article to its copy on a platform
+type CrossPost struct {
+	Provider     string    `json:"provider"`
+	CanonicalURL string    `json:"canonical_url"`
+	RemoteID     string    `json:"remote_id"`
+	URL          string    `json:"url"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,40 @@
+package entity
+
+import "time"
+
+// FeedEntry is a single entry of an RSS, Atom or JSON feed
+type FeedEntry struct {
+	GUID      string
+	Title     string
+	Link      string
+	Summary   string
+	Published time.Time
+}
+
+// FeedState keeps track of a watched feed
+type FeedState struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	LastPolled   time.Time `json:"last_polled"`
+	Seen         []string  `json:"seen"`
+	// Pending are new entries waiting for their scheduled time
+	Pending []PendingShare `json:"pending,omitempty"`
+}
+
+// PendingShare is a feed entry which is shared at DueAt
+type PendingShare struct {
+	GUID    string       `json:"guid"`
+	Article ArticleShare `json:"article"`
+	DueAt   time.Time    `json:"due_at"`
+}
+
+// HasSeen returns true if the entry with guid was already processed
+func (s FeedState) HasSeen(guid string) bool {
+	for _, g := range s.Seen {
+		if g == guid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,21 @@
+package entity
+
+// FrontMatter holds the metadata of a blog post relevant for sharing
+type FrontMatter struct {
+	Title       string     `yaml:"title" toml:"title"`
+	Slug        string     `yaml:"slug" toml:"slug"`
+	URL         string     `yaml:"url" toml:"url"`
+	Description string     `yaml:"description" toml:"description"`
+	Tags        []string   `yaml:"tags" toml:"tags"`
+	CoverImage  string     `yaml:"cover_image" toml:"cover_image"`
+	Social      SocialMeta `yaml:"social" toml:"social"`
+	Syndication []string   `yaml:"syndication" toml:"syndication"`
+}
+
+// SocialMeta is the "social:" block of the front matter
+type SocialMeta struct {
+	Title      string   `yaml:"title" toml:"title"`
+	Comment    string   `yaml:"comment" toml:"comment"`
+	Providers  []string `yaml:"providers" toml:"providers"`
+	Subreddits []string `yaml:"subreddits" toml:"subreddits"`
+}
@@ -15,6 +15,9 @@ type IdentityProvider struct {
 	AccessTokenSecret string     `yaml:"accessTokenSecret"`
 	RefreshToken      string     `yaml:"refreshToken"`
 	ExpiresAt         *time.Time `yaml:"expiry"`
+	// Endpoint is the API URL of self-hosted providers (e.g. the Matrix
+	// homeserver)
+	Endpoint string `yaml:"endpoint"`
 }
 
 type Providers struct {
@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+// ShortLink maps a short code to a long URL
+type ShortLink struct {
+	Code      string    `json:"code"`
+	URL       string    `json:"url"`
+	Clicks    int       `json:"clicks"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+// PostMetrics holds the engagement of a published post
+type PostMetrics struct {
+	Likes    int `json:"likes"`
+	Reposts  int `json:"reposts"`
+	Comments int `json:"comments"`
+}
+
+// MetricsSnapshot are the metrics of a share at a certain point in time
+type MetricsSnapshot struct {
+	ShareID     string      `json:"share_id"`
+	Provider    string      `json:"provider"`
+	PostID      string      `json:"post_id"`
+	Metrics     PostMetrics `json:"metrics"`
+	CollectedAt time.Time   `json:"collected_at"`
+}
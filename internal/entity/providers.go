@@ -4,3 +4,26 @@ type AuthProviderIndex struct {
 	Providers    []string
 	ProvidersMap map[string]string
 }
+
+// ProviderCapabilities describe what a provider supports
+type ProviderCapabilities struct {
+	// MaxLength is the maximum length of a post (0 if unlimited)
+	MaxLength int  `json:"max_length"`
+	Media     bool `json:"media"`
+	Threads   bool `json:"threads"`
+	Edit      bool `json:"edit"`
+	Delete    bool `json:"delete"`
+	Metrics   bool `json:"metrics"`
+	// CrossPost providers take full articles instead of links
+	CrossPost bool `json:"crosspost"`
+	// Subreddits providers submit to the communities named in
+	// ArticleShare.Subreddits
+	Subreddits bool `json:"subreddits"`
+}
+
+// ProviderInfo describes a provider gocial can share to
+type ProviderInfo struct {
+	Name         string               `json:"name"`
+	DisplayName  string               `json:"display_name"`
+	Capabilities ProviderCapabilities `json:"capabilities"`
+}
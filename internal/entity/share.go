@@ -1,11 +1,24 @@
 package entity
 
+import "time"
+
 // ArticleShare is an article to be shared via the share service
 type ArticleShare struct {
 	URL       string `json:"url" form:"url" validate:"required"`
 	Title     string `json:"title" form:"title" validate:"required"`
 	Comment   string `json:"comment" form:"comment" validate:"required"`
 	Providers string `json:"providers" form:"providers" validate:"required"`
+	// DisableUTM opts out of UTM campaign tagging for this share
+	DisableUTM bool `json:"disable_utm" form:"disable_utm"`
+	// Subreddits is a comma separated list of subreddits a link is
+	// submitted to (Reddit only). A flair is selected by its ID or text
+	// with "subreddit:flair".
+	Subreddits string `json:"subreddits,omitempty" form:"subreddits"`
+	// Tags is a comma separated list of tags recorded in the share history
+	Tags string `json:"tags,omitempty" form:"tags"`
+	// OriginalURL is the URL before UTM tagging, tracking and shortening.
+	// It's set by the share service for providers which fetch the article.
+	OriginalURL string `json:"-" form:"-"`
 }
 
 // CommentShare is a comment to be shared via the share service
@@ -13,3 +26,53 @@ type CommentShare struct {
 	// TODO: Any other fields needed?
 	Comment string
 }
+
+// ShareEntry is a record of an article shared via a single provider
+type ShareEntry struct {
+	ID          string     `json:"id"`
+	Provider    string     `json:"provider"`
+	Account     string     `json:"account,omitempty"`
+	AccountID   string     `json:"account_id,omitempty"`
+	URL         string     `json:"url"`
+	OriginalURL string     `json:"original_url"`
+	ShortURL    string     `json:"short_url,omitempty"`
+	TrackingURL string     `json:"tracking_url,omitempty"`
+	PostID      string     `json:"post_id,omitempty"`
+	PostURL     string     `json:"post_url,omitempty"`
+	Title       string     `json:"title"`
+	Comment     string     `json:"comment"`
+	Tags        []string   `json:"tags,omitempty"`
+	SharedAt    time.Time  `json:"shared_at"`
+	EditedAt    *time.Time `json:"edited_at,omitempty"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	Deliveries  []Delivery `json:"deliveries,omitempty"`
+}
+
+// ShareResult identifies the post created by a provider
+type ShareResult struct {
+	PostID  string `json:"post_id"`
+	PostURL string `json:"post_url"`
+	// Deliveries are set by providers posting to several destinations
+	// (e.g. Nostr relays)
+	Deliveries []Delivery `json:"deliveries,omitempty"`
+}
+
+// Delivery is the outcome of sending a post to a single destination
+type Delivery struct {
+	Target   string `json:"target"`
+	Accepted bool   `json:"accepted"`
+	Message  string `json:"message,omitempty"`
+}
+
+// SharePreview is the result of a dry-run: what would be sent to a provider
+type SharePreview struct {
+	Provider    string `json:"provider"`
+	URL         string `json:"url"`
+	OriginalURL string `json:"original_url"`
+	// Text is the visible text of the post
+	Text string `json:"text"`
+	// MaxLength is the maximum number of characters (runes) of Text allowed
+	// by the provider
+	MaxLength int         `json:"max_length,omitempty"`
+	Payload   interface{} `json:"payload"`
+}
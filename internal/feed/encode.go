@@ -0,0 +1,216 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     atomAuthor     `xml:"author"`
+	Links      []atomLink     `xml:"link"`
+	Categories []atomCategory `xml:"category"`
+	Content    *atomContent   `xml:"content,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// Atom encodes the feed as Atom 1.0. self is the URL the feed is served at.
+func (f Feed) Atom(self string) ([]byte, error) {
+	feed := atomFeed{
+		ID:       self,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  f.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: self, Rel: "self", Type: "application/atom+xml"},
+			{Href: f.Link, Rel: "alternate"},
+		},
+	}
+	for _, item := range f.Items {
+		entry := atomEntry{
+			ID:        item.ID,
+			Title:     item.Title,
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Updated:   item.Updated.UTC().Format(time.RFC3339),
+			Author:    atomAuthor{Name: item.Author},
+			Links:     []atomLink{{Href: item.Link, Rel: "alternate"}},
+		}
+		if item.PostURL != "" {
+			entry.Links = append(entry.Links, atomLink{Href: item.PostURL, Rel: "related"})
+		}
+		for _, t := range item.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: t})
+		}
+		if item.Content != "" {
+			entry.Content = &atomContent{Type: "text", Body: item.Content}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return marshalXML(feed)
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Self          rssSelf   `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssSelf struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description,omitempty"`
+	Creator     string   `xml:"dc:creator"`
+	Categories  []string `xml:"category"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	ID          string `xml:",chardata"`
+}
+
+// RSS encodes the feed as RSS 2.0. self is the URL the feed is served at.
+func (f Feed) RSS(self string) ([]byte, error) {
+	feed := rssFeed{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.Link,
+			Description: f.Description,
+			Self:        rssSelf{Href: self, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	if !f.Updated.IsZero() {
+		feed.Channel.LastBuildDate = f.Updated.UTC().Format(time.RFC1123Z)
+	}
+	for _, item := range f.Items {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Content,
+			Creator:     item.Author,
+			Categories:  item.Tags,
+			GUID:        rssGUID{ID: item.ID},
+			PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+		})
+	}
+	return marshalXML(feed)
+}
+
+type jsonFeed struct {
+	Version     string     `json:"version"`
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	HomePageURL string     `json:"home_page_url"`
+	FeedURL     string     `json:"feed_url"`
+	Items       []jsonItem `json:"items"`
+}
+
+type jsonItem struct {
+	ID            string       `json:"id"`
+	URL           string       `json:"url"`
+	ExternalURL   string       `json:"external_url,omitempty"`
+	Title         string       `json:"title"`
+	ContentText   string       `json:"content_text"`
+	DatePublished string       `json:"date_published"`
+	DateModified  string       `json:"date_modified"`
+	Authors       []jsonAuthor `json:"authors"`
+	Tags          []string     `json:"tags,omitempty"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+}
+
+// JSON encodes the feed as JSON Feed 1.1. self is the URL the feed is
+// served at.
+func (f Feed) JSON(self string) ([]byte, error) {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		Description: f.Description,
+		HomePageURL: f.Link,
+		FeedURL:     self,
+		Items:       make([]jsonItem, 0, len(f.Items)),
+	}
+	for _, item := range f.Items {
+		feed.Items = append(feed.Items, jsonItem{
+			ID:            item.ID,
+			URL:           item.Link,
+			ExternalURL:   item.PostURL,
+			Title:         item.Title,
+			ContentText:   item.Content,
+			DatePublished: item.Published.UTC().Format(time.RFC3339),
+			DateModified:  item.Updated.UTC().Format(time.RFC3339),
+			Authors:       []jsonAuthor{{Name: item.Author}},
+			Tags:          item.Tags,
+		})
+	}
+	b, err := json.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't encode JSON feed: %s", err)
+	}
+	return b, nil
+}
+
+// marshalXML encodes v as indented XML document
+func marshalXML(v interface{}) ([]byte, error) {
+	b, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't encode feed: %s", err)
+	}
+	return append([]byte(xml.Header), b...), nil
+}
@@ -0,0 +1,165 @@
+package feed
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/history"
+)
+
+// Filter restricts a feed to the shares of a single provider, account or
+// tag. Empty fields match everything.
+type Filter struct {
+	Provider string
+	Account  string
+	Tag      string
+}
+
+// Feed is the format independent representation of the share history
+type Feed struct {
+	Title       string
+	Description string
+	Link        string
+	Updated     time.Time
+	Items       []Item
+}
+
+// Item is a single share. Link points to the shared article, PostURL to
+// the post on the provider.
+type Item struct {
+	ID        string
+	Title     string
+	Link      string
+	PostURL   string
+	Content   string
+	Author    string
+	Provider  string
+	Tags      []string
+	Published time.Time
+	Updated   time.Time
+}
+
+type Service interface {
+	Feed(Filter) (Feed, error)
+}
+
+type ServiceConfig struct {
+	History     history.Repository
+	Title       string
+	Description string
+	// BaseURL is the public URL gocial is reachable at
+	BaseURL string
+	// Limit is the maximum number of items per feed
+	Limit int
+}
+
+// feedService implements feed.Service
+type feedService struct {
+	history     history.Repository
+	title       string
+	description string
+	baseURL     string
+	limit       int
+}
+
+func NewService(conf ServiceConfig) Service {
+	return feedService{
+		history:     conf.History,
+		title:       conf.Title,
+		description: conf.Description,
+		baseURL:     strings.TrimSuffix(conf.BaseURL, "/"),
+		limit:       conf.Limit,
+	}
+}
+
+// Feed returns the latest shares matching filter, newest first. Deleted
+// shares are left out.
+func (s feedService) Feed(filter Filter) (Feed, error) {
+	entries, err := s.history.GetAll()
+	if err != nil {
+		return Feed{}, fmt.Errorf("Couldn't read share history: %s", err)
+	}
+
+	matching := make([]entity.ShareEntry, 0)
+	for _, e := range entries {
+		if e.DeletedAt == nil && filter.matches(e) {
+			matching = append(matching, e)
+		}
+	}
+	sort.SliceStable(matching, func(i, j int) bool {
+		return matching[i].SharedAt.After(matching[j].SharedAt)
+	})
+	if s.limit > 0 && len(matching) > s.limit {
+		matching = matching[:s.limit]
+	}
+
+	feed := Feed{
+		Title:       s.title,
+		Description: s.description,
+		Link:        s.baseURL + "/",
+		Items:       make([]Item, 0, len(matching)),
+	}
+	for _, e := range matching {
+		item := newItem(e)
+		if item.Updated.After(feed.Updated) {
+			feed.Updated = item.Updated
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed, nil
+}
+
+// matches reports whether e belongs to the filtered feed
+func (f Filter) matches(e entity.ShareEntry) bool {
+	if f.Provider != "" && !strings.EqualFold(f.Provider, e.Provider) {
+		return false
+	}
+	if f.Account != "" && !strings.EqualFold(f.Account, e.Account) {
+		return false
+	}
+	if f.Tag == "" {
+		return true
+	}
+	for _, t := range e.Tags {
+		if strings.EqualFold(f.Tag, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// newItem converts a share entry into a feed item
+func newItem(e entity.ShareEntry) Item {
+	link := e.OriginalURL
+	if link == "" {
+		link = e.URL
+	}
+	title := e.Title
+	if title == "" {
+		title = link
+	}
+	author := e.Account
+	if author == "" {
+		author = e.Provider
+	}
+
+	item := Item{
+		ID:        "urn:gocial:share:" + e.ID,
+		Title:     title,
+		Link:      link,
+		PostURL:   e.PostURL,
+		Content:   e.Comment,
+		Author:    author,
+		Provider:  e.Provider,
+		Tags:      e.Tags,
+		Published: e.SharedAt,
+		Updated:   e.SharedAt,
+	}
+	if e.EditedAt != nil && e.EditedAt.After(e.SharedAt) {
+		item.Updated = *e.EditedAt
+	}
+	return item
+}
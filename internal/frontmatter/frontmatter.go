@@ -0,0 +1,367 @@
+package frontmatter
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/dorneanu/gocial/internal/entity"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported document formats
+const (
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+	FormatOrg  = "org"
+)
+
+// Document is a Markdown file with YAML/TOML front matter or an Org file
+type Document struct {
+	Format string
+	Meta   entity.FrontMatter
+	// frontMatter is the raw front matter without delimiters
+	frontMatter string
+	// body is everything after the front matter
+	body string
+}
+
+// Parse reads the front matter of a Markdown or Org file
+func Parse(filename string, data []byte) (*Document, error) {
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	if strings.EqualFold(filepath.Ext(filename), ".org") {
+		return parseOrg(content)
+	}
+
+	switch {
+	case strings.HasPrefix(content, "---\n"):
+		return parseDelimited(content, "---", FormatYAML)
+	case strings.HasPrefix(content, "+++\n"):
+		return parseDelimited(content, "+++", FormatTOML)
+	}
+	return nil, fmt.Errorf("No front matter found")
+}
+
+// parseDelimited parses front matter enclosed in delimiter lines
+func parseDelimited(content, delimiter, format string) (*Document, error) {
+	rest := strings.TrimPrefix(content, delimiter+"\n")
+	end := strings.Index(rest, "\n"+delimiter+"\n")
+	if end < 0 {
+		if !strings.HasSuffix(rest, "\n"+delimiter) {
+			return nil, fmt.Errorf("Front matter isn't terminated")
+		}
+		end = len(rest) - len(delimiter) - 1
+	}
+
+	doc := &Document{
+		Format:      format,
+		frontMatter: rest[:end+1],
+		body:        strings.TrimPrefix(rest[end+1:], delimiter),
+	}
+
+	var err error
+	if format == FormatYAML {
+		err = yaml.Unmarshal([]byte(doc.frontMatter), &doc.Meta)
+	} else {
+		_, err = toml.Decode(doc.frontMatter, &doc.Meta)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't parse front matter: %s", err)
+	}
+	return doc, nil
+}
+
+// parseOrg reads "#+KEY: value" lines at the beginning of an Org file.
+// Both plain (#+TITLE) and ox-hugo (#+HUGO_SLUG) keywords are supported.
+func parseOrg(content string) (*Document, error) {
+	doc := &Document{Format: FormatOrg, body: content}
+
+	for _, line := range strings.Split(content, "\n") {
+		key, value, ok := orgKeyword(line)
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "TITLE":
+			doc.Meta.Title = value
+		case "SLUG", "HUGO_SLUG":
+			doc.Meta.Slug = value
+		case "URL", "HUGO_URL":
+			doc.Meta.URL = value
+		case "DESCRIPTION", "HUGO_DESCRIPTION":
+			doc.Meta.Description = value
+		case "SOCIAL_TITLE":
+			doc.Meta.Social.Title = value
+		case "SOCIAL_COMMENT":
+			doc.Meta.Social.Comment = value
+		case "SOCIAL_PROVIDERS":
+			doc.Meta.Social.Providers = strings.FieldsFunc(value, func(r rune) bool {
+				return r == ',' || r == ' '
+			})
+		case "SOCIAL_SUBREDDITS":
+			doc.Meta.Social.Subreddits = strings.FieldsFunc(value, func(r rune) bool {
+				return r == ',' || r == ' '
+			})
+		case "SYNDICATION":
+			doc.Meta.Syndication = append(doc.Meta.Syndication, value)
+		}
+	}
+	return doc, nil
+}
+
+// orgKeyword splits a "#+KEY: value" line
+func orgKeyword(line string) (string, string, bool) {
+	if !strings.HasPrefix(line, "#+") {
+		return "", "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(line, "#+"), ":", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return strings.ToUpper(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1]), true
+}
+
+// Article builds an article share from the front matter. The URL is taken
+// from the front matter or derived from baseURL and the slug. If there is no
+// slug, fallbackSlug (usually the file name) is used.
+func (d *Document) Article(baseURL, fallbackSlug string) (entity.ArticleShare, error) {
+	articleURL, err := d.canonicalURL(baseURL, fallbackSlug)
+	if err != nil {
+		return entity.ArticleShare{}, err
+	}
+
+	title := d.Meta.Social.Title
+	if title == "" {
+		title = d.Meta.Title
+	}
+	comment := d.Meta.Social.Comment
+	if comment == "" {
+		comment = d.Meta.Description
+	}
+
+	return entity.ArticleShare{
+		URL:        articleURL,
+		Title:      title,
+		Comment:    comment,
+		Providers:  strings.Join(d.Meta.Social.Providers, ","),
+		Subreddits: strings.Join(d.Meta.Social.Subreddits, ","),
+		Tags:       strings.Join(d.Meta.Tags, ","),
+	}, nil
+}
+
+// CrossPost builds the article syndicated to blogging platforms. Only
+// Markdown files are supported. The URL is derived like in Article.
+func (d *Document) CrossPost(baseURL, fallbackSlug string) (entity.CrossPostArticle, error) {
+	if d.Format == FormatOrg {
+		return entity.CrossPostArticle{}, fmt.Errorf("Cross-posting requires a Markdown file")
+	}
+	articleURL, err := d.canonicalURL(baseURL, fallbackSlug)
+	if err != nil {
+		return entity.CrossPostArticle{}, err
+	}
+
+	return entity.CrossPostArticle{
+		Title:        d.Meta.Title,
+		Description:  d.Meta.Description,
+		CanonicalURL: articleURL,
+		Tags:         d.Meta.Tags,
+		CoverImage:   d.Meta.CoverImage,
+		Body:         strings.TrimLeft(d.body, "\n"),
+	}, nil
+}
+
+// canonicalURL returns the URL of the article
+func (d *Document) canonicalURL(baseURL, fallbackSlug string) (string, error) {
+	articleURL := d.Meta.URL
+	if articleURL == "" || strings.HasPrefix(articleURL, "/") {
+		if baseURL == "" {
+			return "", fmt.Errorf("No base URL configured")
+		}
+
+		path := articleURL
+		if path == "" {
+			slug := d.Meta.Slug
+			if slug == "" {
+				slug = fallbackSlug
+			}
+			path = slug + "/"
+		}
+		articleURL = strings.TrimSuffix(baseURL, "/") + "/" + strings.TrimPrefix(path, "/")
+	}
+	return articleURL, nil
+}
+
+// AddSyndication adds links to the syndication list and returns the new
+// file content. Existing links are kept.
+func (d *Document) AddSyndication(links ...string) ([]byte, error) {
+	for _, l := range links {
+		if !contains(d.Meta.Syndication, l) {
+			d.Meta.Syndication = append(d.Meta.Syndication, l)
+		}
+	}
+
+	switch d.Format {
+	case FormatYAML:
+		return d.writeYAML()
+	case FormatTOML:
+		return d.writeTOML(), nil
+	default:
+		return d.writeOrg(), nil
+	}
+}
+
+// writeYAML updates the syndication key while keeping the rest
+// of the front matter (including comments)
+func (d *Document) writeYAML() ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(d.frontMatter), &root); err != nil {
+		return nil, fmt.Errorf("Couldn't parse front matter: %s", err)
+	}
+	if len(root.Content) == 0 {
+		root.Content = []*yaml.Node{{Kind: yaml.MappingNode}}
+	}
+	mapping := root.Content[0]
+
+	var links yaml.Node
+	if err := links.Encode(d.Meta.Syndication); err != nil {
+		return nil, err
+	}
+
+	replaced := false
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == "syndication" {
+			mapping.Content[i+1] = &links
+			replaced = true
+		}
+	}
+	if !replaced {
+		mapping.Content = append(mapping.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: "syndication"}, &links)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&root); err != nil {
+		return nil, fmt.Errorf("Couldn't marshalize front matter: %s", err)
+	}
+	return []byte("---\n" + buf.String() + "---" + d.body), nil
+}
+
+// tomlSyndication matches the top-level syndication key
+var tomlSyndication = regexp.MustCompile(`^syndication\s*=`)
+
+// writeTOML replaces the top-level syndication array (which may span
+// several lines) or inserts a new one before the first table
+func (d *Document) writeTOML() []byte {
+	quoted := make([]string, 0, len(d.Meta.Syndication))
+	for _, l := range d.Meta.Syndication {
+		quoted = append(quoted, fmt.Sprintf("%q", l))
+	}
+	syndication := fmt.Sprintf("syndication = [%s]", strings.Join(quoted, ", "))
+
+	lines := make([]string, 0)
+	inserted := false
+	depth := 0
+	for _, line := range strings.Split(strings.TrimSuffix(d.frontMatter, "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		// Skip continuation lines of the replaced array
+		if depth > 0 {
+			depth += bracketDepth(trimmed)
+			continue
+		}
+		if tomlSyndication.MatchString(trimmed) && !inserted {
+			lines = append(lines, syndication)
+			inserted = true
+			depth = bracketDepth(trimmed)
+			continue
+		}
+		if strings.HasPrefix(trimmed, "[") && !inserted {
+			lines = append(lines, syndication)
+			inserted = true
+		}
+		lines = append(lines, line)
+	}
+	if !inserted {
+		lines = append(lines, syndication)
+	}
+	return []byte("+++\n" + strings.Join(lines, "\n") + "\n+++" + d.body)
+}
+
+// bracketDepth returns the number of opened minus the number of closed
+// square brackets outside of strings and comments in a TOML line
+func bracketDepth(line string) int {
+	depth := 0
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if r == '\\' && quote == '"' {
+				escaped = true
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return depth
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+		}
+	}
+	return depth
+}
+
+// writeOrg replaces all #+SYNDICATION lines by new ones after the last keyword
+func (d *Document) writeOrg() []byte {
+	lines := strings.Split(d.body, "\n")
+
+	// Find end of the keyword block
+	last := -1
+	for i, line := range lines {
+		if _, _, ok := orgKeyword(line); ok {
+			last = i
+		} else if strings.TrimSpace(line) != "" {
+			break
+		}
+	}
+
+	out := make([]string, 0, len(lines)+len(d.Meta.Syndication))
+	for i, line := range lines {
+		if key, _, ok := orgKeyword(line); !ok || key != "SYNDICATION" {
+			out = append(out, line)
+		}
+		if i == last {
+			for _, l := range d.Meta.Syndication {
+				out = append(out, "#+SYNDICATION: "+l)
+			}
+		}
+	}
+	if last < 0 {
+		synd := make([]string, 0)
+		for _, l := range d.Meta.Syndication {
+			synd = append(synd, "#+SYNDICATION: "+l)
+		}
+		out = append(synd, out...)
+	}
+	return []byte(strings.Join(out, "\n"))
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+// FileHistoryRepository implements history.Repository and keeps
+// all share entries in a single JSON file
+type FileHistoryRepository struct {
+	BasePath string
+	mu       sync.Mutex
+}
+
+func NewFileHistoryRepository(path string) *FileHistoryRepository {
+	return &FileHistoryRepository{
+		BasePath: path,
+	}
+}
+
+// Add appends a new share entry to the history file
+func (fr *FileHistoryRepository) Add(entry entity.ShareEntry) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	entries, err := fr.load()
+	if err != nil {
+		return err
+	}
+	return fr.save(append(entries, entry))
+}
+
+// Update replaces the share entry with the same ID
+func (fr *FileHistoryRepository) Update(entry entity.ShareEntry) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	entries, err := fr.load()
+	if err != nil {
+		return err
+	}
+	for i := range entries {
+		if entries[i].ID == entry.ID {
+			entries[i] = entry
+			return fr.save(entries)
+		}
+	}
+	return fmt.Errorf("Couldn't find share entry: %s", entry.ID)
+}
+
+// GetByID returns the share entry with the given ID
+func (fr *FileHistoryRepository) GetByID(id string) (entity.ShareEntry, error) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	entries, err := fr.load()
+	if err != nil {
+		return entity.ShareEntry{}, err
+	}
+	for _, e := range entries {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return entity.ShareEntry{}, fmt.Errorf("Couldn't find share entry: %s", id)
+}
+
+// GetAll returns all share entries in the order they were added
+func (fr *FileHistoryRepository) GetAll() ([]entity.ShareEntry, error) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	return fr.load()
+}
+
+func (fr *FileHistoryRepository) load() ([]entity.ShareEntry, error) {
+	entries := make([]entity.ShareEntry, 0)
+
+	b, err := ioutil.ReadFile(fr.BasePath)
+	if os.IsNotExist(err) {
+		return entries, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("Couldn't open file: %s", err)
+	}
+
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return nil, fmt.Errorf("Couldn't unmarshalize data: %s", err)
+	}
+	return entries, nil
+}
+
+func (fr *FileHistoryRepository) save(entries []entity.ShareEntry) error {
+	b, err := json.MarshalIndent(entries, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fr.BasePath, b, 0600)
+}
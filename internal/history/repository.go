@@ -0,0 +1,11 @@
+package history
+
+import "github.com/dorneanu/gocial/internal/entity"
+
+// Repository stores a record of every article that has been shared
+type Repository interface {
+	Add(entity.ShareEntry) error
+	Update(entity.ShareEntry) error
+	GetByID(string) (entity.ShareEntry, error)
+	GetAll() ([]entity.ShareEntry, error)
+}
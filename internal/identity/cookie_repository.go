@@ -15,12 +15,17 @@ type CookieIdentityOptions struct {
 	BaseCookieName  string
 	Ctx             echo.Context
 	TokenSigningKey string
+	TokenExpiration time.Duration
+	// Expiration is used for identities without an expiry date
+	Expiration time.Duration
 }
 
 type CookieIdentityRepository struct {
 	baseCookieName  string
 	ctx             echo.Context
 	tokenSigningKey string
+	tokenExpiration time.Duration
+	expiration      time.Duration
 }
 
 func NewCookieIdentityRepository(opts *CookieIdentityOptions) *CookieIdentityRepository {
@@ -28,13 +33,15 @@ func NewCookieIdentityRepository(opts *CookieIdentityOptions) *CookieIdentityRep
 		baseCookieName:  opts.BaseCookieName,
 		ctx:             opts.Ctx,
 		tokenSigningKey: opts.TokenSigningKey,
+		tokenExpiration: opts.TokenExpiration,
+		expiration:      opts.Expiration,
 	}
 }
 
 // Add ...
 func (cr *CookieIdentityRepository) Add(id entity.IdentityProvider, c echo.Context) error {
 	// Generate new JWT token
-	jwtToken, err := jwtutils.NewToken(id, cr.tokenSigningKey)
+	jwtToken, err := jwtutils.NewToken(id, cr.tokenSigningKey, cr.tokenExpiration)
 	if err != nil {
 		return fmt.Errorf("Cannot generate new JWT token: %s", err)
 	}
@@ -42,8 +49,7 @@ func (cr *CookieIdentityRepository) Add(id entity.IdentityProvider, c echo.Conte
 	// Check if expiresAt is set
 	var expiresAt time.Time
 	if id.ExpiresAt.IsZero() {
-		// TODO: change this
-		expiresAt = time.Now().Add(720 * time.Hour)
+		expiresAt = time.Now().Add(cr.expiration)
 	} else {
 		expiresAt = *id.ExpiresAt
 	}
@@ -73,6 +79,9 @@ func (cr *CookieIdentityRepository) GetByProvider(provider string, c echo.Contex
 	token, err := jwt.ParseWithClaims(cookie.Value, &jwtutils.JwtCustomClaims{}, func(token *jwt.Token) (interface{}, error) {
 		return []byte(cr.tokenSigningKey), nil
 	})
+	if err != nil || token == nil {
+		return entity.IdentityProvider{}, fmt.Errorf("Couldn't validate JWT token: %s", err)
+	}
 
 	// Check if valid
 	if claims, ok := token.Claims.(*jwtutils.JwtCustomClaims); ok && token.Valid {
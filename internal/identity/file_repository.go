@@ -1,10 +1,9 @@
 package identity
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
+	"io/ioutil"
 	"os"
 
 	"github.com/dorneanu/gocial/internal/entity"
@@ -24,12 +23,19 @@ func NewFileIdentityRepo(path string) *FileIdentityRepository {
 	}
 }
 
+// Add adds a new identity or replaces the existing one of the same provider
 func (fr *FileIdentityRepository) Add(id entity.IdentityProvider, c echo.Context) error {
+	for i := range fr.identities {
+		if fr.identities[i].Provider == id.Provider {
+			fr.identities[i] = id
+			return nil
+		}
+	}
 	fr.identities = append(fr.identities, id)
 	return nil
 }
 
-func (fr *FileIdentityRepository) GetByProvider(c echo.Context, provider string) (entity.IdentityProvider, error) {
+func (fr *FileIdentityRepository) GetByProvider(provider string, c echo.Context) (entity.IdentityProvider, error) {
 	for _, id := range fr.identities {
 		if id.Provider == provider {
 			return id, nil
@@ -38,29 +44,54 @@ func (fr *FileIdentityRepository) GetByProvider(c echo.Context, provider string)
 	return entity.IdentityProvider{}, fmt.Errorf("Couldn't find identity")
 }
 
-func (fr *FileIdentityRepository) Save() error {
-	f, err := os.Create(fr.BasePath)
-	if err != nil {
-		return err
+// Delete removes the identity of the given provider
+func (fr *FileIdentityRepository) Delete(provider string, c echo.Context) error {
+	identities := make([]entity.IdentityProvider, 0, len(fr.identities))
+	for _, id := range fr.identities {
+		if id.Provider != provider {
+			identities = append(identities, id)
+		}
 	}
-	defer f.Close()
+	fr.identities = identities
+	return nil
+}
+
+// GetAll returns all stored identities
+func (fr *FileIdentityRepository) GetAll() []entity.IdentityProvider {
+	return fr.identities
+}
 
+// Save writes the identities to a file only readable by the user. They hold
+// access tokens and private keys.
+func (fr *FileIdentityRepository) Save() error {
 	b, err := json.MarshalIndent(fr.identities, "", "\t")
 	if err != nil {
 		return err
 	}
 
-	_, err = io.Copy(f, bytes.NewReader(b))
-	return err
+	// Files written by older versions were readable by everyone
+	if err := os.Chmod(fr.BasePath, 0600); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("Couldn't restrict permissions: %s", err)
+	}
+	return ioutil.WriteFile(fr.BasePath, b, 0600)
 }
 
 func (fr *FileIdentityRepository) Load() error {
 	f, err := os.Open(fr.BasePath)
-	if err != nil {
+	if os.IsNotExist(err) {
+		// Nothing has been saved yet
+		return nil
+	} else if err != nil {
 		return fmt.Errorf("Couldn't open file: %s", err)
 	}
 	defer f.Close()
 
+	if info, err := f.Stat(); err == nil && info.Mode().Perm()&0077 != 0 {
+		if err := os.Chmod(fr.BasePath, 0600); err != nil {
+			return fmt.Errorf("Couldn't restrict permissions: %s", err)
+		}
+	}
+
 	err = json.NewDecoder(f).Decode(&fr.identities)
 	if err != nil {
 		return fmt.Errorf("Couldn't unmarshalize data: %s", err)
@@ -26,8 +26,8 @@ type Token struct {
 	claims       *JwtCustomClaims
 }
 
-// NewToken returns a signed JWT token
-func NewToken(id entity.IdentityProvider, signingKey string) (string, error) {
+// NewToken returns a signed JWT token which expires after expiration
+func NewToken(id entity.IdentityProvider, signingKey string, expiration time.Duration) (string, error) {
 	// Create the Claims
 	claims := &JwtCustomClaims{
 		UserName:          id.UserName,
@@ -39,7 +39,7 @@ func NewToken(id entity.IdentityProvider, signingKey string) (string, error) {
 		AccessTokenSecret: id.AccessTokenSecret,
 		RefreshToken:      id.RefreshToken,
 		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(time.Hour * 72).Unix(),
+			ExpiresAt: time.Now().Add(expiration).Unix(),
 			Issuer:    id.Provider,
 		},
 	}
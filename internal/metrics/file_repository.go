@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+// FileMetricsRepository implements metrics.Repository
+// and keeps all snapshots in a single JSON file
+type FileMetricsRepository struct {
+	BasePath string
+	mu       sync.Mutex
+}
+
+func NewFileMetricsRepository(path string) *FileMetricsRepository {
+	return &FileMetricsRepository{
+		BasePath: path,
+	}
+}
+
+// Add appends a new snapshot
+func (fr *FileMetricsRepository) Add(snapshot entity.MetricsSnapshot) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	snapshots, err := fr.load()
+	if err != nil {
+		return err
+	}
+	return fr.save(append(snapshots, snapshot))
+}
+
+// GetByShare returns all snapshots of a share ordered by collection time
+func (fr *FileMetricsRepository) GetByShare(shareID string) ([]entity.MetricsSnapshot, error) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	snapshots, err := fr.load()
+	if err != nil {
+		return nil, err
+	}
+
+	series := make([]entity.MetricsSnapshot, 0)
+	for _, s := range snapshots {
+		if s.ShareID == shareID {
+			series = append(series, s)
+		}
+	}
+	return series, nil
+}
+
+func (fr *FileMetricsRepository) load() ([]entity.MetricsSnapshot, error) {
+	snapshots := make([]entity.MetricsSnapshot, 0)
+
+	b, err := ioutil.ReadFile(fr.BasePath)
+	if os.IsNotExist(err) {
+		return snapshots, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("Couldn't open file: %s", err)
+	}
+
+	if err := json.Unmarshal(b, &snapshots); err != nil {
+		return nil, fmt.Errorf("Couldn't unmarshalize data: %s", err)
+	}
+	return snapshots, nil
+}
+
+func (fr *FileMetricsRepository) save(snapshots []entity.MetricsSnapshot) error {
+	b, err := json.MarshalIndent(snapshots, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fr.BasePath, b, 0600)
+}
@@ -0,0 +1,9 @@
+package metrics
+
+import "github.com/dorneanu/gocial/internal/entity"
+
+// Repository stores metrics snapshots of shared posts
+type Repository interface {
+	Add(entity.MetricsSnapshot) error
+	GetByShare(string) ([]entity.MetricsSnapshot, error)
+}
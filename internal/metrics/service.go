@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/history"
+	"github.com/dorneanu/gocial/internal/share"
+)
+
+type Service interface {
+	Collect(context.Context, []entity.IdentityProvider) ([]entity.MetricsSnapshot, error)
+	Run(context.Context, time.Duration, []entity.IdentityProvider)
+	TimeSeries(string) ([]entity.MetricsSnapshot, error)
+}
+
+type ServiceConfig struct {
+	Repo         Repository
+	History      history.Repository
+	ShareService share.Service
+}
+
+// metricsService implements metrics.Service
+type metricsService struct {
+	repo         Repository
+	history      history.Repository
+	shareService share.Service
+}
+
+func NewService(conf ServiceConfig) Service {
+	return metricsService{
+		repo:         conf.Repo,
+		history:      conf.History,
+		shareService: conf.ShareService,
+	}
+}
+
+// Collect fetches the current metrics of every share in the history whose
+// provider supports metrics and for which an identity is available
+func (s metricsService) Collect(ctx context.Context, identities []entity.IdentityProvider) ([]entity.MetricsSnapshot, error) {
+	entries, err := s.history.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create one repository per provider
+	repos := make(map[string]share.MetricsRepository)
+	for _, id := range identities {
+		repo, err := s.shareService.GetShareRepo(id)
+		if err != nil {
+			continue
+		}
+		if metricsRepo, ok := repo.(share.MetricsRepository); ok {
+			repos[id.Provider] = metricsRepo
+		}
+	}
+
+	snapshots := make([]entity.MetricsSnapshot, 0)
+	var errs []string
+	for _, e := range entries {
+		repo, ok := repos[e.Provider]
+		if !ok || e.PostID == "" || e.DeletedAt != nil {
+			continue
+		}
+
+		m, err := repo.GetMetrics(ctx, e.PostID)
+		if errors.Is(err, share.ErrNotSupported) {
+			continue
+		} else if err != nil {
+			errs = append(errs, fmt.Sprintf("%s (%s): %s", e.ID, e.Provider, err))
+			continue
+		}
+
+		snapshot := entity.MetricsSnapshot{
+			ShareID:     e.ID,
+			Provider:    e.Provider,
+			PostID:      e.PostID,
+			Metrics:     m,
+			CollectedAt: time.Now(),
+		}
+		if err := s.repo.Add(snapshot); err != nil {
+			return snapshots, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if len(errs) > 0 {
+		return snapshots, fmt.Errorf("Couldn't collect metrics: %s", strings.Join(errs, "; "))
+	}
+	return snapshots, nil
+}
+
+// Run collects metrics every interval until ctx is cancelled
+func (s metricsService) Run(ctx context.Context, interval time.Duration, identities []entity.IdentityProvider) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Collect(ctx, identities); err != nil {
+			log.Printf("%s\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// TimeSeries returns all snapshots of a share
+func (s metricsService) TimeSeries(shareID string) ([]entity.MetricsSnapshot, error) {
+	return s.repo.GetByShare(shareID)
+}
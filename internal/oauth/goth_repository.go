@@ -3,6 +3,7 @@ package oauth
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/dorneanu/gocial/internal/entity"
 	"github.com/gorilla/sessions"
@@ -16,9 +17,17 @@ type GothRepository struct {
 	jwtSigningKey string
 }
 
-func NewGothRepository(providerIndex entity.AuthProviderIndex, signingKey string) *GothRepository {
+// SessionConfig configures the cookie which holds the OAuth session
+type SessionConfig struct {
+	Key    string
+	MaxAge time.Duration
+	// Secure should be set when serving over HTTPS
+	Secure bool
+}
+
+func NewGothRepository(providerIndex entity.AuthProviderIndex, signingKey string, session SessionConfig) *GothRepository {
 	// Setup cookie store
-	setupCookies()
+	setupCookies(session)
 
 	return &GothRepository{
 		jwtSigningKey: signingKey,
@@ -27,17 +36,12 @@ func NewGothRepository(providerIndex entity.AuthProviderIndex, signingKey string
 }
 
 // setupCookies sets up cookies
-func setupCookies() {
-	// TODO: Customize this somewhere else
-	key := "Secret-session-key"
-	maxAge := 86400 * 30 // 30 days
-	isProd := false      // set to true when serving over HTTPS
-
-	store := sessions.NewCookieStore([]byte(key))
-	store.MaxAge(maxAge)
+func setupCookies(session SessionConfig) {
+	store := sessions.NewCookieStore([]byte(session.Key))
+	store.MaxAge(int(session.MaxAge.Seconds()))
 	store.Options.Path = "/"
 	store.Options.HttpOnly = true // HttpOnly should always be enabled
-	store.Options.Secure = isProd
+	store.Options.Secure = session.Secure
 	gothic.Store = store
 }
 
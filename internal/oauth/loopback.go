@@ -0,0 +1,111 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/provider"
+	"github.com/labstack/echo/v4"
+)
+
+type loopbackResult struct {
+	identity entity.IdentityProvider
+	err      error
+}
+
+// LoopbackLogin runs the OAuth workflow for a single provider the way desktop
+// applications do: a temporary HTTP server is started on a random loopback
+// port and receives the callback. showURL is called with the URL the user
+// has to open in the browser. The server is shut down as soon as the
+// callback was handled or ctx is cancelled.
+func LoopbackLogin(ctx context.Context, conf OAuthConfig, session SessionConfig, showURL func(string)) (entity.IdentityProvider, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return entity.IdentityProvider{}, fmt.Errorf("Couldn't listen on loopback interface: %s", err)
+	}
+	baseURL := fmt.Sprintf("http://%s", listener.Addr().String())
+
+	// Register provider with a callback pointing to the temporary server
+	conf.CallbackURL = fmt.Sprintf("%s/auth/callback/%s", baseURL, conf.ProviderName)
+	providerIndex := SetupAuthProviders([]OAuthConfig{conf})
+	// No tokens are issued here, so there is no signing key
+	repo := NewGothRepository(providerIndex, "", session)
+
+	state, err := newState()
+	if err != nil {
+		listener.Close()
+		return entity.IdentityProvider{}, err
+	}
+
+	done := make(chan loopbackResult, 1)
+	finish := func(r loopbackResult) {
+		select {
+		case done <- r:
+		default:
+		}
+	}
+
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.Listener = listener
+	e.GET("/auth/:provider", repo.HandleAuth)
+	e.GET("/auth/callback/:provider", func(c echo.Context) error {
+		// OAuth1 providers don't send back the state parameter. Their
+		// request token is bound to the session by goth instead.
+		p, _ := provider.Get(conf.ProviderName)
+		if !p.OAuth1 && c.QueryParam("state") != state {
+			finish(loopbackResult{err: fmt.Errorf("Invalid state parameter")})
+			return c.String(http.StatusBadRequest, "Invalid state parameter")
+		}
+
+		if err := repo.HandleAuthCallback(c); err != nil {
+			finish(loopbackResult{err: err})
+			return err
+		}
+		id, ok := c.Get("identity-provider").(entity.IdentityProvider)
+		if !ok {
+			finish(loopbackResult{err: fmt.Errorf("Couldn't complete authentication")})
+			return nil
+		}
+
+		finish(loopbackResult{identity: id})
+		return c.String(http.StatusOK, "Login successful. You can close this window now.")
+	})
+
+	go func() {
+		if err := e.Start(""); err != nil && err != http.ErrServerClosed {
+			finish(loopbackResult{err: err})
+		}
+	}()
+	showURL(fmt.Sprintf("%s/auth/%s?state=%s", baseURL, conf.ProviderName, state))
+
+	var result loopbackResult
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result.err = ctx.Err()
+	}
+
+	// Give the browser a moment to receive the last response
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	e.Shutdown(shutdownCtx)
+
+	return result.identity, result.err
+}
+
+// newState returns a random value for the OAuth state parameter
+func newState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("Couldn't generate state: %s", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -1,12 +1,9 @@
 package oauth
 
 import (
-	"strings"
-
 	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/provider"
 	"github.com/markbates/goth"
-	"github.com/markbates/goth/providers/linkedin"
-	"github.com/markbates/goth/providers/twitter"
 )
 
 type OAuthConfig struct {
@@ -52,40 +49,29 @@ func (s oauthService) ProviderIndex() entity.AuthProviderIndex {
 	return s.providerIndex
 }
 
-// SetupAuthProviders configures lits of supported authentication providers
+// SetupAuthProviders configures the registered providers for the given
+// OAuth configs and returns an index of them
 func SetupAuthProviders(confs []OAuthConfig) entity.AuthProviderIndex {
-	oauthConfs := make(map[string]OAuthConfig)
-	for _, c := range confs {
-		oauthConfs[c.ProviderName] = c
+	index := entity.AuthProviderIndex{
+		Providers:    make([]string, 0),
+		ProvidersMap: make(map[string]string),
 	}
+	for _, c := range confs {
+		p, ok := provider.Get(c.ProviderName)
+		if !ok || p.OAuth == nil {
+			continue
+		}
 
-	m := make(map[string]string)
-	for _, oauthConf := range oauthConfs {
-		m[oauthConf.ProviderName] = oauthConf.ProviderName
-
-		// TODO: Change this to switch case?
-		if oauthConf.ProviderName == "linkedin" {
-			idpLinkedin := linkedin.New(
-				oauthConf.ClientID,
-				oauthConf.ClientSecret,
-				oauthConf.CallbackURL,
-				strings.Join(oauthConf.Scopes, " "))
-
-			goth.UseProviders(idpLinkedin)
-		} else if oauthConf.ProviderName == "twitter" {
-			idpTwitter := twitter.New(
-				oauthConf.ClientID,
-				oauthConf.ClientSecret,
-				oauthConf.CallbackURL,
-			)
-			goth.UseProviders(idpTwitter)
+		scopes := c.Scopes
+		if len(scopes) == 0 {
+			scopes = p.Scopes
 		}
-	}
+		goth.UseProviders(p.OAuth(c.ClientID, c.ClientSecret, c.CallbackURL, scopes))
 
-	var keys []string
-	for k := range m {
-		keys = append(keys, k)
+		if _, ok := index.ProvidersMap[p.Name]; !ok {
+			index.Providers = append(index.Providers, p.Name)
+		}
+		index.ProvidersMap[p.Name] = p.DisplayName
 	}
-
-	return entity.AuthProviderIndex{Providers: keys, ProvidersMap: m}
+	return index
 }
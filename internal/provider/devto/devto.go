@@ -0,0 +1,46 @@
+// Package devto cross-posts articles as drafts to dev.to or any other Forem
+// instance. The API key is kept in the identity store.
+package devto
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dorneanu/gocial/internal/crosspost"
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/provider"
+)
+
+func init() {
+	provider.Register(provider.Provider{
+		ProviderInfo: entity.ProviderInfo{
+			Name:        "devto",
+			DisplayName: "DEV",
+			Capabilities: entity.ProviderCapabilities{
+				CrossPost: true,
+			},
+		},
+		Connect: connect,
+		NewCrossPostRepository: func(id entity.IdentityProvider) (crosspost.Repository, error) {
+			return NewCrossPostRepository(id), nil
+		},
+	})
+}
+
+// connect checks the API key and stores the user it belongs to
+func connect(ctx context.Context, id entity.IdentityProvider) (entity.IdentityProvider, error) {
+	if id.AccessToken == "" {
+		return id, fmt.Errorf("API key is required")
+	}
+
+	var user User
+	if err := NewCrossPostRepository(id).call(ctx, http.MethodGet, "/users/me", nil, &user); err != nil {
+		return id, err
+	}
+	id.UserID = strconv.FormatInt(user.ID, 10)
+	id.UserName = user.Username
+	id.UserDescription = user.Name
+	return id, nil
+}
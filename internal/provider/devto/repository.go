@@ -0,0 +1,189 @@
+package devto
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dorneanu/gocial/internal/crosspost"
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+const (
+	// defaultEndpoint is the API used unless the identity names another
+	// Forem instance
+	defaultEndpoint = "https://dev.to/api"
+	// devtoMaxTags is the number of tags an article may have
+	devtoMaxTags = 4
+)
+
+// CrossPostRepository implements crosspost.Repository using the Forem API
+// (https://developers.forem.com/api/v1)
+type CrossPostRepository struct {
+	endpoint string
+	apiKey   string
+	client   *http.Client
+}
+
+func NewCrossPostRepository(identity entity.IdentityProvider) *CrossPostRepository {
+	endpoint := identity.Endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+	return &CrossPostRepository{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		apiKey:   identity.AccessToken,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// User is the owner of an API key
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+// Article is an article as returned by the API
+type Article struct {
+	ID        int64  `json:"id"`
+	URL       string `json:"url"`
+	Published bool   `json:"published"`
+}
+
+type articleRequest struct {
+	Article articlePayload `json:"article"`
+}
+
+type articlePayload struct {
+	Title        string `json:"title"`
+	BodyMarkdown string `json:"body_markdown"`
+	// Published is only sent when creating an article, so updates don't
+	// unpublish articles which were published in the meantime
+	Published    *bool    `json:"published,omitempty"`
+	CanonicalURL string   `json:"canonical_url"`
+	Description  string   `json:"description,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	MainImage    string   `json:"main_image,omitempty"`
+}
+
+// CreateDraft creates an unpublished article
+func (r *CrossPostRepository) CreateDraft(ctx context.Context, article entity.CrossPostArticle) (entity.ShareResult, error) {
+	published := false
+	payload := newPayload(article)
+	payload.Published = &published
+
+	var created Article
+	if err := r.call(ctx, http.MethodPost, "/articles", articleRequest{payload}, &created); err != nil {
+		return entity.ShareResult{}, err
+	}
+	return entity.ShareResult{
+		PostID:  strconv.FormatInt(created.ID, 10),
+		PostURL: created.URL,
+	}, nil
+}
+
+// UpdateDraft updates the article with the given ID
+func (r *CrossPostRepository) UpdateDraft(ctx context.Context, id string, article entity.CrossPostArticle) (entity.ShareResult, error) {
+	var updated Article
+	if err := r.call(ctx, http.MethodPut, "/articles/"+id, articleRequest{newPayload(article)}, &updated); err != nil {
+		return entity.ShareResult{}, err
+	}
+	return entity.ShareResult{
+		PostID:  strconv.FormatInt(updated.ID, 10),
+		PostURL: updated.URL,
+	}, nil
+}
+
+func newPayload(article entity.CrossPostArticle) articlePayload {
+	return articlePayload{
+		Title:        article.Title,
+		BodyMarkdown: article.Body,
+		CanonicalURL: article.CanonicalURL,
+		Description:  article.Description,
+		Tags:         tags(article.Tags),
+		MainImage:    article.CoverImage,
+	}
+}
+
+// tags returns at most devtoMaxTags tags. Tags may only contain lowercase
+// letters and digits.
+func tags(list []string) []string {
+	result := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, t := range list {
+		tag := strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return unicode.ToLower(r)
+			}
+			return -1
+		}, t)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+		if len(result) == devtoMaxTags {
+			break
+		}
+	}
+	return result
+}
+
+// call sends a request to the API and decodes the response (if result
+// isn't nil)
+func (r *CrossPostRepository) call(ctx context.Context, method, path string, params interface{}, result interface{}) error {
+	var body io.Reader
+	if params != nil {
+		b, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("Couldn't marshal request: %s", err)
+		}
+		body = bytes.NewReader(b)
+	}
+
+	// Create new HTTP request
+	req, err := http.NewRequestWithContext(ctx, method, r.endpoint+path, body)
+	if err != nil {
+		return fmt.Errorf("Couldn't create request: %s", err)
+	}
+	req.Header.Set("api-key", r.apiKey)
+	req.Header.Set("Accept", "application/vnd.forem.api-v1+json")
+	if params != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Send request
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Couldn't call DEV: %s", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound && method == http.MethodPut:
+		return fmt.Errorf("%w: %s", crosspost.ErrNotFound, path)
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("DEV rate limit exceeded (retry after %ss)", resp.Header.Get("Retry-After"))
+	case resp.StatusCode >= 300:
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("DEV returned %s: %s", resp.Status, apiErr.Error)
+	}
+
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("Couldn't unmarshalize response: %s", err)
+		}
+	}
+	return nil
+}
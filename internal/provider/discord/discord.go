@@ -0,0 +1,33 @@
+// Package discord shares articles to Discord channels via webhooks
+package discord
+
+import (
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/provider"
+	"github.com/dorneanu/gocial/internal/share"
+)
+
+// Provider returns the registry entry of a Discord webhook. The webhook URL
+// is the credential, so no identity is required.
+func Provider(conf config.DiscordConfig) provider.Provider {
+	displayName := conf.DisplayName
+	if displayName == "" {
+		displayName = conf.Name
+	}
+	return provider.Provider{
+		ProviderInfo: entity.ProviderInfo{
+			Name:        conf.Name,
+			DisplayName: displayName,
+			Capabilities: entity.ProviderCapabilities{
+				MaxLength: discordMaxCharacters,
+				Edit:      true,
+				Delete:    true,
+			},
+		},
+		NoIdentity: true,
+		NewRepository: func(client config.ProviderConfig, id entity.IdentityProvider) (share.Repository, error) {
+			return NewShareRepository(conf), nil
+		},
+	}
+}
@@ -0,0 +1,217 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+const (
+	// discordMaxCharacters is the maximum length of an embed description
+	discordMaxCharacters = 4096
+
+	// discordMaxTitle is the maximum length of an embed title
+	discordMaxTitle = 256
+)
+
+// Message is a Discord webhook message
+// (https://discord.com/developers/docs/resources/webhook#execute-webhook)
+type Message struct {
+	Username  string  `json:"username,omitempty"`
+	AvatarURL string  `json:"avatar_url,omitempty"`
+	Embeds    []Embed `json:"embeds"`
+}
+
+type Embed struct {
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Description string     `json:"description,omitempty"`
+	Color       int        `json:"color,omitempty"`
+	Thumbnail   *Thumbnail `json:"thumbnail,omitempty"`
+}
+
+type Thumbnail struct {
+	URL string `json:"url"`
+}
+
+// ShareRepository posts messages to a Discord webhook
+type ShareRepository struct {
+	conf   config.DiscordConfig
+	client *http.Client
+	// pageClient fetches articles for their thumbnail
+	pageClient *http.Client
+}
+
+func NewShareRepository(conf config.DiscordConfig) *ShareRepository {
+	return &ShareRepository{
+		conf:       conf,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		pageClient: newPageClient(),
+	}
+}
+
+// composeMessage builds an embed with the title, URL, comment and thumbnail
+// of an article. The thumbnail is only fetched from the article if fetch is
+// set. The original URL is fetched so the request neither counts as a click
+// nor goes through the shortener.
+func (d *ShareRepository) composeMessage(ctx context.Context, article entity.ArticleShare, fetch bool) (Message, error) {
+	if n := len([]rune(article.Comment)); n > discordMaxCharacters {
+		return Message{}, fmt.Errorf("Post max characters exceeded: %d (allowed: %d)", n, discordMaxCharacters)
+	}
+	title := article.Title
+	if runes := []rune(title); len(runes) > discordMaxTitle {
+		title = string(runes[:discordMaxTitle-1]) + "…"
+	}
+
+	embed := Embed{
+		Title:       title,
+		URL:         article.URL,
+		Description: article.Comment,
+		Color:       d.conf.Color,
+	}
+	thumbnail := d.conf.ThumbnailURL
+	if d.conf.FetchThumbnail && fetch {
+		page := article.OriginalURL
+		if page == "" {
+			page = article.URL
+		}
+		if image, err := fetchImage(ctx, d.pageClient, page); err == nil && image != "" {
+			thumbnail = image
+		}
+	}
+	if thumbnail != "" {
+		embed.Thumbnail = &Thumbnail{URL: thumbnail}
+	}
+
+	return Message{
+		Username:  d.conf.Username,
+		AvatarURL: d.conf.AvatarURL,
+		Embeds:    []Embed{embed},
+	}, nil
+}
+
+// endpoint returns the webhook URL extended by path and query parameters
+func (d *ShareRepository) endpoint(path string, params url.Values) (string, error) {
+	u, err := url.Parse(d.conf.WebhookURL)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't parse webhook URL: %s", err)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	q := u.Query()
+	for k, v := range params {
+		q[k] = v
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// do sends a request to the webhook and decodes the response into result
+// (if not nil)
+func (d *ShareRepository) do(ctx context.Context, method, endpoint string, payload interface{}, result interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("Couldn't marshal message: %s", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	// Create new HTTP request
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("Couldn't create request: %s", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Send request
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Couldn't call Discord webhook: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("Discord rate limit exceeded (retry after %ss)", resp.Header.Get("Retry-After"))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("Discord webhook returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("Couldn't unmarshalize response: %s", err)
+		}
+	}
+	return nil
+}
+
+// PreviewArticle returns the message which would be sent. The article isn't
+// fetched, so the preview shows the configured thumbnail only.
+func (d *ShareRepository) PreviewArticle(ctx context.Context, article entity.ArticleShare) (entity.SharePreview, error) {
+	msg, err := d.composeMessage(ctx, article, false)
+	if err != nil {
+		return entity.SharePreview{}, err
+	}
+	return entity.SharePreview{
+		Text:      article.Comment,
+		MaxLength: discordMaxCharacters,
+		Payload:   msg,
+	}, nil
+}
+
+// ShareArticle posts the article to the webhook. The ID of the created
+// message is returned as post ID.
+func (d *ShareRepository) ShareArticle(ctx context.Context, article entity.ArticleShare) (entity.ShareResult, error) {
+	msg, err := d.composeMessage(ctx, article, true)
+	if err != nil {
+		return entity.ShareResult{}, err
+	}
+
+	// wait=true makes Discord return the created message
+	endpoint, err := d.endpoint("", url.Values{"wait": {"true"}})
+	if err != nil {
+		return entity.ShareResult{}, err
+	}
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := d.do(ctx, http.MethodPost, endpoint, msg, &created); err != nil {
+		return entity.ShareResult{}, err
+	}
+	return entity.ShareResult{PostID: created.ID}, nil
+}
+
+// EditPost replaces the embed of a message sent by the webhook
+func (d *ShareRepository) EditPost(ctx context.Context, postID string, article entity.ArticleShare) error {
+	msg, err := d.composeMessage(ctx, article, true)
+	if err != nil {
+		return err
+	}
+	endpoint, err := d.endpoint("/messages/"+url.PathEscape(postID), nil)
+	if err != nil {
+		return err
+	}
+	return d.do(ctx, http.MethodPatch, endpoint, msg, nil)
+}
+
+// DeletePost deletes a message sent by the webhook
+func (d *ShareRepository) DeletePost(ctx context.Context, postID string) error {
+	endpoint, err := d.endpoint("/messages/"+url.PathEscape(postID), nil)
+	if err != nil {
+		return err
+	}
+	return d.do(ctx, http.MethodDelete, endpoint, nil, nil)
+}
@@ -0,0 +1,153 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// maxPageSize limits how much of an article is read to find its image
+const maxPageSize = 1 << 20
+
+// nonPublicRanges are IPv4 ranges which aren't covered by the net.IP methods:
+// "this network" (RFC 1122) and the carrier-grade NAT range (RFC 6598)
+var nonPublicRanges = []*net.IPNet{
+	{IP: net.IP{0, 0, 0, 0}, Mask: net.CIDRMask(8, 32)},
+	{IP: net.IP{100, 64, 0, 0}, Mask: net.CIDRMask(10, 32)},
+}
+
+// newPageClient returns a client which only connects to public addresses.
+// Article URLs are user supplied and must not reach internal services.
+func newPageClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return fmt.Errorf("Address %s is not public", host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("Too many redirects")
+			}
+			return checkPageURL(req.URL)
+		},
+	}
+}
+
+// publicIP reports whether ip is a globally routable unicast address
+func publicIP(ip net.IP) bool {
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, r := range nonPublicRanges {
+		if r.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkPageURL only allows HTTP(S) URLs
+func checkPageURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("Unsupported URL scheme: %s", u.Scheme)
+	}
+	return nil
+}
+
+// fetchImage returns the og:image (or twitter:image) of a web page. Relative
+// image URLs are resolved against the page URL.
+func fetchImage(ctx context.Context, client *http.Client, pageURL string) (string, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return "", fmt.Errorf("Invalid URL %s: %s", pageURL, err)
+	}
+	if err := checkPageURL(u); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't create request: %s", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't fetch %s: %s", pageURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Couldn't fetch %s: %s", pageURL, resp.Status)
+	}
+
+	image := findImage(io.LimitReader(resp.Body, maxPageSize))
+	if image == "" {
+		return "", nil
+	}
+	ref, err := url.Parse(image)
+	if err != nil {
+		return "", fmt.Errorf("Invalid image URL %s: %s", image, err)
+	}
+	return u.ResolveReference(ref).String(), nil
+}
+
+// findImage scans the head of an HTML document for image meta tags
+func findImage(r io.Reader) string {
+	var fallback string
+	z := html.NewTokenizer(r)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return fallback
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) == "head" {
+				return fallback
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			if string(name) != "meta" || !hasAttr {
+				continue
+			}
+			var property, content string
+			for {
+				key, value, more := z.TagAttr()
+				switch strings.ToLower(string(key)) {
+				case "property", "name":
+					property = strings.ToLower(string(value))
+				case "content":
+					content = string(value)
+				}
+				if !more {
+					break
+				}
+			}
+			switch property {
+			case "og:image":
+				return content
+			case "twitter:image":
+				fallback = content
+			}
+		}
+	}
+}
@@ -0,0 +1,28 @@
+// Package email sends shared articles as newsletter emails via SMTP
+package email
+
+import (
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/provider"
+	"github.com/dorneanu/gocial/internal/share"
+)
+
+// Provider returns the registry entry of an email target. The SMTP
+// credentials are part of the configuration, so no identity is required.
+func Provider(conf config.EmailConfig) provider.Provider {
+	displayName := conf.DisplayName
+	if displayName == "" {
+		displayName = conf.Name
+	}
+	return provider.Provider{
+		ProviderInfo: entity.ProviderInfo{
+			Name:        conf.Name,
+			DisplayName: displayName,
+		},
+		NoIdentity: true,
+		NewRepository: func(client config.ProviderConfig, id entity.IdentityProvider) (share.Repository, error) {
+			return NewRepository(conf), nil
+		},
+	}
+}
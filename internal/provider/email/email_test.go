@@ -0,0 +1,194 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+// sink is an SMTP server which accepts every recipient except the ones
+// starting with "unknown"
+type sink struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	messages map[string]string
+	sessions int
+}
+
+func newSink(t *testing.T) *sink {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &sink{listener: l, messages: make(map[string]string)}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+// conf returns a target sending to the sink without TLS
+func (s *sink) conf(to ...string) config.EmailConfig {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return config.EmailConfig{
+		Name: "newsletter",
+		Host: addr.IP.String(),
+		Port: addr.Port,
+		TLS:  "none",
+		From: "Blog <news@example.com>",
+		To:   to,
+	}
+}
+
+func (s *sink) serve(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	s.mu.Lock()
+	s.sessions++
+	s.mu.Unlock()
+
+	tp.PrintfLine("220 sink ESMTP")
+	var rcpt string
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch cmd {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250 sink")
+		case "MAIL", "NOOP":
+			tp.PrintfLine("250 2.1.0 Ok")
+		case "RSET":
+			rcpt = ""
+			tp.PrintfLine("250 2.0.0 Ok")
+		case "RCPT":
+			address := strings.Trim(line[len("RCPT TO:"):], "<> ")
+			if strings.HasPrefix(address, "unknown") {
+				tp.PrintfLine("550 5.1.1 No such user")
+				continue
+			}
+			rcpt = address
+			tp.PrintfLine("250 2.1.5 Ok")
+		case "DATA":
+			tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.messages[rcpt] = string(data)
+			s.mu.Unlock()
+			tp.PrintfLine("250 2.0.0 Queued")
+		case "QUIT":
+			tp.PrintfLine("221 2.0.0 Bye")
+			return
+		default:
+			tp.PrintfLine("502 5.5.2 Command not recognized")
+		}
+	}
+}
+
+func TestShareArticlePartialFailure(t *testing.T) {
+	s := newSink(t)
+	conf := s.conf("a@example.com", "unknown@example.com", "Bea <b@example.com>")
+	conf.BatchSize = 2
+	repo := NewRepository(conf)
+
+	result, err := repo.ShareArticle(context.Background(), entity.ArticleShare{URL: "https://example.com/post", Title: "Post", Comment: "New post"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []entity.Delivery{
+		{Target: "a@example.com", Accepted: true},
+		{Target: "unknown@example.com", Message: "550 5.1.1 No such user"},
+		{Target: "b@example.com", Accepted: true},
+	}
+	if len(result.Deliveries) != len(want) {
+		t.Fatalf("deliveries = %+v", result.Deliveries)
+	}
+	for i, d := range result.Deliveries {
+		if d != want[i] {
+			t.Errorf("delivery %d = %+v; want %+v", i, d, want[i])
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessions != 2 {
+		t.Errorf("%d SMTP sessions; want 2 (batch size 2)", s.sessions)
+	}
+	if len(s.messages) != 2 {
+		t.Fatalf("sink received %d messages; want 2", len(s.messages))
+	}
+	for address, to := range map[string]string{
+		"a@example.com": "<a@example.com>",
+		"b@example.com": `"Bea" <b@example.com>`,
+	} {
+		msg, ok := s.messages[address]
+		if !ok {
+			t.Errorf("no message for %s", address)
+			continue
+		}
+		if !strings.Contains(msg, "\nTo: "+to+"\n") || !strings.Contains(msg, "\nSubject: Post\n") {
+			t.Errorf("message for %s:\n%s", address, msg)
+		}
+	}
+}
+
+func TestShareArticleAllRejected(t *testing.T) {
+	s := newSink(t)
+	repo := NewRepository(s.conf("unknown@example.com", "unknown2@example.com"))
+
+	_, err := repo.ShareArticle(context.Background(), entity.ArticleShare{URL: "https://example.com/post", Title: "Post"})
+	want := "Couldn't send email to any recipient: unknown@example.com: 550 5.1.1 No such user; unknown2@example.com: 550 5.1.1 No such user"
+	if err == nil || err.Error() != want {
+		t.Errorf("error = %v; want %s", err, want)
+	}
+}
+
+func TestShareArticleConnectionFailure(t *testing.T) {
+	s := newSink(t)
+	conf := s.conf("a@example.com")
+	s.listener.Close()
+
+	_, err := NewRepository(conf).ShareArticle(context.Background(), entity.ArticleShare{URL: "https://example.com/post", Title: "Post"})
+	if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("a@example.com: Couldn't connect to 127.0.0.1:%d", conf.Port)) {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestPreviewHidesRecipients(t *testing.T) {
+	repo := NewRepository(config.EmailConfig{From: "news@example.com", To: []string{"a@example.com", "b@example.com"}})
+
+	preview, err := repo.PreviewArticle(context.Background(), entity.ArticleShare{URL: "https://example.com/post", Title: "Post"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := preview.Payload.(Preview); p.Recipients != 2 {
+		t.Errorf("recipients = %d; want 2", p.Recipients)
+	}
+	data, _ := json.Marshal(preview)
+	if strings.Contains(string(data), "a@example.com") {
+		t.Errorf("preview discloses recipients: %s", data)
+	}
+}
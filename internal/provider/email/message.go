@@ -0,0 +1,198 @@
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+const (
+	defaultSubject = "{{ .Title }}"
+
+	defaultText = `{{ .Title }}
+
+{{ with .Comment }}{{ . }}
+
+{{ end }}Read more: {{ .URL }}
+{{ with .Unsubscribe }}
+--
+Unsubscribe: {{ . }}
+{{ end }}`
+
+	defaultHTML = `<!DOCTYPE html>
+<html>
+<body>
+<h1><a href="{{ .URL }}">{{ .Title }}</a></h1>
+{{ with .Comment }}<p>{{ . }}</p>
+{{ end }}<p><a href="{{ .URL }}">Read more</a></p>
+{{ with .Unsubscribe }}<p style="font-size: small"><a href="{{ . }}">Unsubscribe</a></p>
+{{ end }}</body>
+</html>
+`
+)
+
+// templateData is passed to the subject, text and HTML templates
+type templateData struct {
+	entity.ArticleShare
+	// Target is the name of the email target
+	Target      string
+	Unsubscribe string
+	SharedAt    time.Time
+}
+
+// Content is the rendered content of a newsletter
+type Content struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
+
+// render renders the subject, text and HTML of an article
+func (r *Repository) render(article entity.ArticleShare) (Content, error) {
+	data := templateData{
+		ArticleShare: article,
+		Target:       r.conf.Name,
+		Unsubscribe:  r.conf.ListUnsubscribe,
+		SharedAt:     time.Now().UTC(),
+	}
+
+	subject, err := renderText(orDefault(r.conf.Subject, defaultSubject), data)
+	if err != nil {
+		return Content{}, fmt.Errorf("Couldn't render subject: %s", err)
+	}
+	text, err := renderText(orDefault(r.conf.Text, defaultText), data)
+	if err != nil {
+		return Content{}, fmt.Errorf("Couldn't render text: %s", err)
+	}
+	html, err := renderHTML(orDefault(r.conf.HTML, defaultHTML), data)
+	if err != nil {
+		return Content{}, fmt.Errorf("Couldn't render HTML: %s", err)
+	}
+
+	return Content{
+		// Line breaks in the subject would start new headers
+		Subject: strings.Join(strings.Fields(subject), " "),
+		Text:    text,
+		HTML:    html,
+	}, nil
+}
+
+// message builds a multipart/alternative message to a single recipient
+func (r *Repository) message(content Content, from, to *mail.Address, messageID string) ([]byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, text string }{
+		{"text/plain; charset=utf-8", content.Text},
+		{"text/html; charset=utf-8", content.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.text)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	headers := [][2]string{
+		{"From", from.String()},
+		{"To", to.String()},
+		{"Subject", mime.QEncoding.Encode("utf-8", content.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", messageID},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + parts.Boundary()},
+		// Keep vacation responders and auto-replies from answering
+		{"Auto-Submitted", "auto-generated"},
+		{"Precedence", "bulk"},
+		{"X-Auto-Response-Suppress", "All"},
+	}
+	if r.conf.ReplyTo != "" {
+		replyTo, err := mail.ParseAddress(r.conf.ReplyTo)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid reply-to address %q: %s", r.conf.ReplyTo, err)
+		}
+		headers = append(headers, [2]string{"Reply-To", replyTo.String()})
+	}
+	if r.conf.ListUnsubscribe != "" {
+		headers = append(headers, [2]string{"List-Unsubscribe", "<" + r.conf.ListUnsubscribe + ">"})
+		if strings.HasPrefix(r.conf.ListUnsubscribe, "https://") {
+			headers = append(headers, [2]string{"List-Unsubscribe-Post", "List-Unsubscribe=One-Click"})
+		}
+	}
+	headers = append(headers, [2]string{"List-Id", fmt.Sprintf("%s <%s.%s>", r.conf.Name, r.conf.Name, domain(from.Address))})
+
+	var msg bytes.Buffer
+	for _, h := range headers {
+		fmt.Fprintf(&msg, "%s: %s\r\n", h[0], h[1])
+	}
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// newMessageID returns a unique message ID in the domain of the sender
+func newMessageID(from string) string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().Unix(), hex.EncodeToString(b), domain(from))
+}
+
+// domain returns the domain part of an address
+func domain(address string) string {
+	return address[strings.LastIndex(address, "@")+1:]
+}
+
+func orDefault(text, def string) string {
+	if text == "" {
+		return def
+	}
+	return text
+}
+
+func renderText(text string, data interface{}) (string, error) {
+	tmpl, err := template.New("email").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't parse template: %s", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("Couldn't execute template: %s", err)
+	}
+	return buf.String(), nil
+}
+
+// renderHTML escapes the article fields while rendering
+func renderHTML(text string, data interface{}) (string, error) {
+	tmpl, err := htmltemplate.New("email").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't parse template: %s", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("Couldn't execute template: %s", err)
+	}
+	return buf.String(), nil
+}
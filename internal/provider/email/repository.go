@@ -0,0 +1,276 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+const (
+	// defaultTimeout limits every SMTP command unless configured otherwise
+	defaultTimeout = 30 * time.Second
+	// defaultBatchSize is the number of messages sent over one connection
+	defaultBatchSize = 50
+)
+
+// Repository sends shared articles as emails to the recipients of a target
+type Repository struct {
+	conf config.EmailConfig
+}
+
+// Preview is the email which would be sent to every recipient. Only the
+// number of recipients is shown, the addresses aren't disclosed.
+type Preview struct {
+	From       string `json:"from"`
+	Recipients int    `json:"recipients"`
+	Content
+}
+
+func NewRepository(conf config.EmailConfig) *Repository {
+	return &Repository{conf: conf}
+}
+
+// PreviewArticle returns the rendered email without sending it
+func (r *Repository) PreviewArticle(ctx context.Context, article entity.ArticleShare) (entity.SharePreview, error) {
+	content, err := r.render(article)
+	if err != nil {
+		return entity.SharePreview{}, err
+	}
+	return entity.SharePreview{
+		Text: content.Text,
+		Payload: Preview{
+			From:       r.conf.From,
+			Recipients: len(r.conf.To),
+			Content:    content,
+		},
+	}, nil
+}
+
+// ShareArticle sends a message to every recipient. Recipients rejected by
+// the server don't stop the others from getting the message. The result
+// holds the delivery of every recipient; an error is only returned if
+// nobody got the message.
+func (r *Repository) ShareArticle(ctx context.Context, article entity.ArticleShare) (entity.ShareResult, error) {
+	content, err := r.render(article)
+	if err != nil {
+		return entity.ShareResult{}, err
+	}
+	from, err := mail.ParseAddress(r.conf.From)
+	if err != nil {
+		return entity.ShareResult{}, fmt.Errorf("Invalid sender %q: %s", r.conf.From, err)
+	}
+	recipients := make([]*mail.Address, 0, len(r.conf.To))
+	for _, to := range r.conf.To {
+		address, err := mail.ParseAddress(to)
+		if err != nil {
+			return entity.ShareResult{}, fmt.Errorf("Invalid recipient %q: %s", to, err)
+		}
+		recipients = append(recipients, address)
+	}
+
+	// Bounces go to the envelope sender
+	sender := from.Address
+	if r.conf.BounceAddress != "" {
+		bounce, err := mail.ParseAddress(r.conf.BounceAddress)
+		if err != nil {
+			return entity.ShareResult{}, fmt.Errorf("Invalid bounce address %q: %s", r.conf.BounceAddress, err)
+		}
+		sender = bounce.Address
+	}
+
+	batchSize := r.conf.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	deliveries := make([]entity.Delivery, 0, len(recipients))
+	for start := 0; start < len(recipients); start += batchSize {
+		end := start + batchSize
+		if end > len(recipients) {
+			end = len(recipients)
+		}
+		batch := recipients[start:end]
+
+		if start > 0 && r.conf.BatchDelay > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(r.conf.BatchDelay):
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			deliveries = append(deliveries, failed(batch, err)...)
+			continue
+		}
+		deliveries = append(deliveries, r.sendBatch(ctx, sender, from, content, batch)...)
+	}
+
+	// The share only fails if nobody got the message. Otherwise a retry
+	// would send it to all recipients again.
+	sent := 0
+	rejected := make([]string, 0)
+	for _, d := range deliveries {
+		if d.Accepted {
+			sent++
+		} else {
+			rejected = append(rejected, fmt.Sprintf("%s: %s", d.Target, d.Message))
+		}
+	}
+	if sent == 0 {
+		return entity.ShareResult{}, fmt.Errorf("Couldn't send email to any recipient: %s", strings.Join(rejected, "; "))
+	}
+	return entity.ShareResult{Deliveries: deliveries}, nil
+}
+
+// sendBatch sends a message to every recipient over a single connection.
+// It returns the delivery of every recipient.
+func (r *Repository) sendBatch(ctx context.Context, sender string, from *mail.Address, content Content, recipients []*mail.Address) []entity.Delivery {
+	conn, c, err := r.dial(ctx)
+	if err != nil {
+		return failed(recipients, err)
+	}
+	defer c.Close()
+
+	// net/smtp doesn't support contexts
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	deliveries := make([]entity.Delivery, 0, len(recipients))
+	for i, to := range recipients {
+		msg, err := r.message(content, from, to, newMessageID(from.Address))
+		if err != nil {
+			deliveries = append(deliveries, failed(recipients[i:i+1], fmt.Errorf("Couldn't build message: %s", err))...)
+			continue
+		}
+
+		conn.SetDeadline(time.Now().Add(r.timeout()))
+		err = send(c, sender, to.Address, msg)
+
+		// Replies of the server only concern this recipient
+		var smtpErr *textproto.Error
+		if errors.As(err, &smtpErr) {
+			deliveries = append(deliveries, entity.Delivery{Target: to.Address, Message: fmt.Sprintf("%d %s", smtpErr.Code, smtpErr.Msg)})
+			if err := c.Reset(); err != nil {
+				return append(deliveries, failed(recipients[i+1:], fmt.Errorf("Couldn't reset SMTP session: %s", err))...)
+			}
+			continue
+		} else if err != nil {
+			return append(deliveries, failed(recipients[i:], err)...)
+		}
+		deliveries = append(deliveries, entity.Delivery{Target: to.Address, Accepted: true})
+	}
+
+	// All messages were accepted at this point
+	c.Quit()
+	return deliveries
+}
+
+// failed returns a rejected delivery for every recipient
+func failed(recipients []*mail.Address, err error) []entity.Delivery {
+	deliveries := make([]entity.Delivery, 0, len(recipients))
+	for _, to := range recipients {
+		deliveries = append(deliveries, entity.Delivery{Target: to.Address, Message: err.Error()})
+	}
+	return deliveries
+}
+
+// dial connects and authenticates to the SMTP server
+func (r *Repository) dial(ctx context.Context) (net.Conn, *smtp.Client, error) {
+	addr := net.JoinHostPort(r.conf.Host, strconv.Itoa(r.port()))
+	tlsConf := &tls.Config{ServerName: r.conf.Host}
+	dialer := &net.Dialer{Timeout: r.timeout()}
+
+	var conn net.Conn
+	var err error
+	if r.conf.TLS == "tls" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConf)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("Couldn't connect to %s: %s", addr, err)
+	}
+	conn.SetDeadline(time.Now().Add(r.timeout()))
+
+	c, err := smtp.NewClient(conn, r.conf.Host)
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("Couldn't connect to %s: %s", addr, err)
+	}
+
+	if r.conf.TLS == "" || r.conf.TLS == "starttls" {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			c.Close()
+			return nil, nil, fmt.Errorf("%s doesn't support STARTTLS", addr)
+		}
+		if err := c.StartTLS(tlsConf); err != nil {
+			c.Close()
+			return nil, nil, fmt.Errorf("Couldn't start TLS: %s", err)
+		}
+	}
+
+	// PlainAuth refuses to send credentials unencrypted except to localhost
+	if r.conf.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", r.conf.Username, r.conf.Password, r.conf.Host)); err != nil {
+			c.Close()
+			return nil, nil, fmt.Errorf("Couldn't authenticate: %s", err)
+		}
+	}
+	return conn, c, nil
+}
+
+// send delivers a message within the current session
+func send(c *smtp.Client, sender, recipient string, msg []byte) error {
+	if err := c.Mail(sender); err != nil {
+		return err
+	}
+	if err := c.Rcpt(recipient); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// port returns the configured port or the default port of the TLS mode
+func (r *Repository) port() int {
+	switch {
+	case r.conf.Port > 0:
+		return r.conf.Port
+	case r.conf.TLS == "tls":
+		return 465
+	case r.conf.TLS == "none":
+		return 25
+	default:
+		return 587
+	}
+}
+
+func (r *Repository) timeout() time.Duration {
+	if r.conf.Timeout > 0 {
+		return r.conf.Timeout
+	}
+	return defaultTimeout
+}
@@ -0,0 +1,72 @@
+// Package hashnode cross-posts articles as drafts to a Hashnode publication.
+// The personal access token and the publication are kept in the identity
+// store.
+package hashnode
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dorneanu/gocial/internal/crosspost"
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/provider"
+)
+
+func init() {
+	provider.Register(provider.Provider{
+		ProviderInfo: entity.ProviderInfo{
+			Name:        "hashnode",
+			DisplayName: "Hashnode",
+			Capabilities: entity.ProviderCapabilities{
+				CrossPost: true,
+			},
+		},
+		Connect: connect,
+		NewCrossPostRepository: func(id entity.IdentityProvider) (crosspost.Repository, error) {
+			return NewCrossPostRepository(id), nil
+		},
+	})
+}
+
+const (
+	publicationByHost = `query ($host: String) {
+  me { username }
+  publication(host: $host) { id title }
+}`
+	publicationByID = `query ($id: ObjectId) {
+  me { username }
+  publication(id: $id) { id title }
+}`
+)
+
+// connect checks the token and the publication (its host, e.g.
+// blog.example.com, or ID). The publication ID is stored as user ID.
+func connect(ctx context.Context, id entity.IdentityProvider) (entity.IdentityProvider, error) {
+	if id.AccessToken == "" || id.UserID == "" {
+		return id, fmt.Errorf("Token and publication are required")
+	}
+
+	query, variables := publicationByID, map[string]interface{}{"id": id.UserID}
+	if strings.Contains(id.UserID, ".") {
+		query, variables = publicationByHost, map[string]interface{}{"host": id.UserID}
+	}
+
+	var data struct {
+		Me struct {
+			Username string `json:"username"`
+		} `json:"me"`
+		Publication *Publication `json:"publication"`
+	}
+	if err := NewCrossPostRepository(id).call(ctx, query, variables, &data); err != nil {
+		return id, err
+	}
+	if data.Publication == nil {
+		return id, fmt.Errorf("Publication not found: %s", id.UserID)
+	}
+
+	id.UserID = data.Publication.ID
+	id.UserName = data.Me.Username
+	id.UserDescription = data.Publication.Title
+	return id, nil
+}
@@ -0,0 +1,226 @@
+package hashnode
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dorneanu/gocial/internal/crosspost"
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+const (
+	// defaultEndpoint is the GraphQL API used unless the identity names
+	// another one
+	defaultEndpoint = "https://gql.hashnode.com"
+	// draftURL is where drafts can be edited
+	draftURL = "https://hashnode.com/draft/%s"
+	// hashnodeMaxSubtitle is the maximum length of a subtitle
+	hashnodeMaxSubtitle = 150
+)
+
+const (
+	createDraftMutation = `mutation ($input: CreateDraftInput!) {
+  createDraft(input: $input) { draft { id } }
+}`
+	updateDraftMutation = `mutation ($input: UpdateDraftInput!) {
+  updateDraft(input: $input) { draft { id } }
+}`
+)
+
+// CrossPostRepository implements crosspost.Repository using the Hashnode
+// GraphQL API (https://apidocs.hashnode.com)
+type CrossPostRepository struct {
+	endpoint      string
+	token         string
+	publicationID string
+	client        *http.Client
+}
+
+func NewCrossPostRepository(identity entity.IdentityProvider) *CrossPostRepository {
+	endpoint := identity.Endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+	return &CrossPostRepository{
+		endpoint:      endpoint,
+		token:         identity.AccessToken,
+		publicationID: identity.UserID,
+		client:        &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Publication is a Hashnode blog
+type Publication struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// Tag references a Hashnode tag by its slug
+type Tag struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+type coverImage struct {
+	CoverImageURL string `json:"coverImageURL"`
+}
+
+// draftInput holds the fields of CreateDraftInput and UpdateDraftInput
+type draftInput struct {
+	ID                 string      `json:"id,omitempty"`
+	PublicationID      string      `json:"publicationId,omitempty"`
+	Title              string      `json:"title"`
+	Subtitle           string      `json:"subtitle,omitempty"`
+	ContentMarkdown    string      `json:"contentMarkdown"`
+	OriginalArticleURL string      `json:"originalArticleURL"`
+	Tags               []Tag       `json:"tags"`
+	CoverImageOptions  *coverImage `json:"coverImageOptions,omitempty"`
+}
+
+type draftPayload struct {
+	Draft struct {
+		ID string `json:"id"`
+	} `json:"draft"`
+}
+
+// CreateDraft creates a draft in the publication
+func (r *CrossPostRepository) CreateDraft(ctx context.Context, article entity.CrossPostArticle) (entity.ShareResult, error) {
+	input := newInput(article)
+	input.PublicationID = r.publicationID
+
+	var data struct {
+		CreateDraft draftPayload `json:"createDraft"`
+	}
+	if err := r.call(ctx, createDraftMutation, map[string]interface{}{"input": input}, &data); err != nil {
+		return entity.ShareResult{}, err
+	}
+	return newResult(data.CreateDraft), nil
+}
+
+// UpdateDraft updates the draft with the given ID
+func (r *CrossPostRepository) UpdateDraft(ctx context.Context, id string, article entity.CrossPostArticle) (entity.ShareResult, error) {
+	input := newInput(article)
+	input.ID = id
+
+	var data struct {
+		UpdateDraft draftPayload `json:"updateDraft"`
+	}
+	if err := r.call(ctx, updateDraftMutation, map[string]interface{}{"input": input}, &data); err != nil {
+		return entity.ShareResult{}, err
+	}
+	return newResult(data.UpdateDraft), nil
+}
+
+func newInput(article entity.CrossPostArticle) draftInput {
+	input := draftInput{
+		Title:              article.Title,
+		Subtitle:           truncate(article.Description, hashnodeMaxSubtitle),
+		ContentMarkdown:    article.Body,
+		OriginalArticleURL: article.CanonicalURL,
+		Tags:               tags(article.Tags),
+	}
+	if article.CoverImage != "" {
+		input.CoverImageOptions = &coverImage{CoverImageURL: article.CoverImage}
+	}
+	return input
+}
+
+func newResult(payload draftPayload) entity.ShareResult {
+	return entity.ShareResult{
+		PostID:  payload.Draft.ID,
+		PostURL: fmt.Sprintf(draftURL, payload.Draft.ID),
+	}
+}
+
+// tags turns tag names into slugs (lowercase words joined by dashes)
+func tags(list []string) []Tag {
+	result := make([]Tag, 0)
+	seen := make(map[string]bool)
+	for _, name := range list {
+		words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		slug := strings.Join(words, "-")
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		result = append(result, Tag{Slug: slug, Name: name})
+	}
+	return result
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// graphQLError is an error returned by the API
+type graphQLError struct {
+	Message    string `json:"message"`
+	Extensions struct {
+		Code string `json:"code"`
+	} `json:"extensions"`
+}
+
+// call runs a GraphQL query and decodes its data into result
+func (r *CrossPostRepository) call(ctx context.Context, query string, variables map[string]interface{}, result interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return fmt.Errorf("Couldn't marshal request: %s", err)
+	}
+
+	// Create new HTTP request
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("Couldn't create request: %s", err)
+	}
+	req.Header.Set("Authorization", r.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send request
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Couldn't call Hashnode: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("Hashnode rate limit exceeded")
+	}
+
+	var gqlResp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphQLError  `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&gqlResp); err != nil {
+		return fmt.Errorf("Couldn't unmarshalize response (%s): %s", resp.Status, err)
+	}
+	if len(gqlResp.Errors) > 0 {
+		e := gqlResp.Errors[0]
+		if e.Extensions.Code == "NOT_FOUND" {
+			return fmt.Errorf("%w: %s", crosspost.ErrNotFound, e.Message)
+		}
+		return fmt.Errorf("Hashnode returned an error: %s", e.Message)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Hashnode returned %s", resp.Status)
+	}
+
+	if err := json.Unmarshal(gqlResp.Data, result); err != nil {
+		return fmt.Errorf("Couldn't unmarshalize data: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/identity"
+	"github.com/labstack/echo/v4"
+)
+
+// IdentityRepository wraps an identity repository. Providers which don't
+// need an identity get one holding only their name. They post with the
+// credentials of the operator (e.g. the team's Slack webhook), so unless the
+// repository is the local one of the CLI, the session must belong to one of
+// the configured operators.
+type IdentityRepository struct {
+	identity.Repository
+	// Local is set for the identity store of the CLI
+	Local bool
+	// Operators lists the accounts (<provider>:<user ID>) which may use
+	// providers without an identity
+	Operators []string
+}
+
+// GetByProvider returns the stored identity of a provider
+func (r IdentityRepository) GetByProvider(name string, c echo.Context) (entity.IdentityProvider, error) {
+	id, err := r.Repository.GetByProvider(name, c)
+	if err == nil {
+		return id, nil
+	}
+	if p, ok := Get(name); ok && p.NoIdentity {
+		if !r.IsOperator(c) {
+			return id, fmt.Errorf("%s can only be used by operators", name)
+		}
+		return entity.IdentityProvider{Provider: name}, nil
+	}
+	return id, err
+}
+
+// IsOperator reports whether c carries the identity of one of the
+// operators. The user of the local identity store always is one.
+func (r IdentityRepository) IsOperator(c echo.Context) bool {
+	if r.Local {
+		return true
+	}
+	if c == nil {
+		return false
+	}
+	for _, op := range r.Operators {
+		parts := strings.SplitN(op, ":", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			continue
+		}
+		if p, ok := Get(parts[0]); !ok || p.NoIdentity {
+			continue
+		}
+		// User IDs can't be chosen or taken over like user names
+		id, err := r.Repository.GetByProvider(parts[0], c)
+		if err == nil && id.Provider == parts[0] && id.UserID == parts[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAll returns all stored identities and, for the local store, the
+// identities of all providers which don't need one
+func (r IdentityRepository) GetAll() []entity.IdentityProvider {
+	all := make([]entity.IdentityProvider, 0)
+	if lister, ok := r.Repository.(interface {
+		GetAll() []entity.IdentityProvider
+	}); ok {
+		all = append(all, lister.GetAll()...)
+	}
+	if !r.Local {
+		return all
+	}
+
+	for _, p := range All() {
+		if !p.NoIdentity {
+			continue
+		}
+		stored := false
+		for _, id := range all {
+			stored = stored || id.Provider == p.Name
+		}
+		if !stored {
+			all = append(all, entity.IdentityProvider{Provider: p.Name})
+		}
+	}
+	return all
+}
@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/identity"
+	"github.com/labstack/echo/v4"
+)
+
+func init() {
+	Register(Provider{ProviderInfo: entity.ProviderInfo{Name: "test-oauth"}})
+	Register(Provider{ProviderInfo: entity.ProviderInfo{Name: "test-other"}})
+	Register(Provider{ProviderInfo: entity.ProviderInfo{Name: "test-hook"}, NoIdentity: true})
+}
+
+const testSigningKey = "test-signing-key-of-sufficient-length"
+
+func newCookieRepository() *identity.CookieIdentityRepository {
+	return identity.NewCookieIdentityRepository(&identity.CookieIdentityOptions{
+		BaseCookieName:  "gocial",
+		TokenSigningKey: testSigningKey,
+		TokenExpiration: time.Hour,
+		Expiration:      time.Hour,
+	})
+}
+
+// loginContext returns a request context carrying the identity cookie of
+// id. The cookie is stored under the name of provider.
+func loginContext(t *testing.T, repo *identity.CookieIdentityRepository, provider string, id *entity.IdentityProvider) echo.Context {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if id != nil {
+		expiresAt := time.Now().Add(time.Hour)
+		id.ExpiresAt = &expiresAt
+		rec := httptest.NewRecorder()
+		if err := repo.Add(*id, e.NewContext(req, rec)); err != nil {
+			t.Fatal(err)
+		}
+		for _, cookie := range rec.Result().Cookies() {
+			cookie.Name = "gocial-" + provider
+			req.AddCookie(cookie)
+		}
+	}
+	return e.NewContext(req, httptest.NewRecorder())
+}
+
+func TestNoIdentityProvidersRequireOperator(t *testing.T) {
+	cookies := newCookieRepository()
+	repo := IdentityRepository{
+		Repository: cookies,
+		Operators:  []string{"test-oauth:1234", "test-hook:1", "malformed"},
+	}
+
+	tests := []struct {
+		name     string
+		provider string
+		id       *entity.IdentityProvider
+		allowed  bool
+	}{
+		{name: "anonymous", provider: "test-oauth"},
+		{
+			name:     "random login",
+			provider: "test-oauth",
+			id:       &entity.IdentityProvider{Provider: "test-oauth", UserID: "999", UserName: "mallory"},
+		},
+		{
+			name:     "user name of operator ID",
+			provider: "test-oauth",
+			id:       &entity.IdentityProvider{Provider: "test-oauth", UserID: "999", UserName: "1234"},
+		},
+		{
+			name:     "operator ID of other provider",
+			provider: "test-other",
+			id:       &entity.IdentityProvider{Provider: "test-other", UserID: "1234"},
+		},
+		{
+			name:     "cookie of other provider renamed",
+			provider: "test-oauth",
+			id:       &entity.IdentityProvider{Provider: "test-other", UserID: "1234"},
+		},
+		{
+			name:     "operator",
+			provider: "test-oauth",
+			id:       &entity.IdentityProvider{Provider: "test-oauth", UserID: "1234", UserName: "alice"},
+			allowed:  true,
+		},
+	}
+	for _, tt := range tests {
+		c := loginContext(t, cookies, tt.provider, tt.id)
+		if got := repo.IsOperator(c); got != tt.allowed {
+			t.Errorf("%s: IsOperator = %t; want %t", tt.name, got, tt.allowed)
+		}
+
+		id, err := repo.GetByProvider("test-hook", c)
+		if tt.allowed {
+			if err != nil || id.Provider != "test-hook" {
+				t.Errorf("%s: GetByProvider = %+v, %v; want identity of test-hook", tt.name, id, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), "only be used by operators") {
+			t.Errorf("%s: GetByProvider = %+v, %v; want operator error", tt.name, id, err)
+		}
+	}
+}
+
+func TestLocalIdentityRepository(t *testing.T) {
+	repo := IdentityRepository{Repository: identity.NewFileIdentityRepo(t.TempDir() + "/ids.json"), Local: true}
+	if !repo.IsOperator(nil) {
+		t.Error("IsOperator = false; want true for the local store")
+	}
+	if _, err := repo.GetByProvider("test-hook", nil); err != nil {
+		t.Errorf("GetByProvider: %s", err)
+	}
+	if _, err := repo.GetByProvider("test-oauth", nil); err == nil {
+		t.Error("GetByProvider of a provider without identity succeeded")
+	}
+
+	found := false
+	for _, id := range repo.GetAll() {
+		found = found || id.Provider == "test-hook"
+	}
+	if !found {
+		t.Error("GetAll doesn't list test-hook")
+	}
+	if ids := (IdentityRepository{Repository: newCookieRepository()}).GetAll(); len(ids) != 0 {
+		t.Errorf("GetAll of the cookie store = %v; want none", ids)
+	}
+}
@@ -0,0 +1,32 @@
+// Package linkedin shares articles as LinkedIn UGC posts
+package linkedin
+
+import (
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/provider"
+	"github.com/dorneanu/gocial/internal/share"
+	"github.com/markbates/goth"
+	gothlinkedin "github.com/markbates/goth/providers/linkedin"
+)
+
+func init() {
+	provider.Register(provider.Provider{
+		ProviderInfo: entity.ProviderInfo{
+			Name:        "linkedin",
+			DisplayName: "LinkedIn",
+			Capabilities: entity.ProviderCapabilities{
+				MaxLength: linkedinMaxCharacters,
+				Delete:    true,
+				Metrics:   true,
+			},
+		},
+		Scopes: []string{"r_emailaddress", "r_liteprofile", "w_member_social"},
+		OAuth: func(clientID, clientSecret, callbackURL string, scopes []string) goth.Provider {
+			return gothlinkedin.New(clientID, clientSecret, callbackURL, scopes...)
+		},
+		NewRepository: func(client config.ProviderConfig, identity entity.IdentityProvider) (share.Repository, error) {
+			return NewShareRepository(identity), nil
+		},
+	})
+}
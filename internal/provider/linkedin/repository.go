@@ -0,0 +1,237 @@
+package linkedin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"unicode/utf8"
+
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+const (
+	// API URL for User Generated Content (UGC)
+	linkedinUGCAPI = "https://api.linkedin.com/v2/ugcPosts"
+
+	// Maximum length of the share commentary
+	linkedinMaxCharacters = 3000
+
+	// API URL for likes and comments of a post
+	linkedinSocialActionsAPI = "https://api.linkedin.com/v2/socialActions"
+)
+
+// LinkedinUGCShareMedia describes the media to be shared
+//
+// Check out https://docs.microsoft.com/en-us/linkedin/marketing/integrations/community-management/shares/ugc-post-api?tabs=http#sharemedia
+type LinkedinUGCShareMedia struct {
+	Status      string `json:"status"`
+	Description struct {
+		Text string `json:"text"`
+	} `json:"description"`
+	OriginalURL string `json:"originalUrl"`
+	Title       struct {
+		Text string `json:"text"`
+	} `json:"title"`
+}
+
+// LinkedinUGCShareContent defines meta data of content to be shared
+//
+// Also check https://docs.microsoft.com/en-us/linkedin/marketing/integrations/community-management/shares/ugc-post-api?tabs=http#sharecontent
+type LinkedinUGCShareContent struct {
+	ShareCommentary struct {
+		Text string `json:"text"`
+	} `json:"shareCommentary"`
+	ShareMediaCategory string                  `json:"shareMediaCategory"`
+	Media              []LinkedinUGCShareMedia `json:"media"`
+}
+
+// LinkedinUGCPost defines the schema for a UGC post
+//
+// Also check https://docs.microsoft.com/en-us/linkedin/marketing/integrations/community-management/shares/ugc-post-api?tabs=http#schema
+type LinkedinUGCSharePost struct {
+	Author          string `json:"author"`
+	LifecycleState  string `json:"lifecycleState"`
+	SpecificContent struct {
+		ShareContent LinkedinUGCShareContent `json:"com.linkedin.ugc.ShareContent"`
+	} `json:"specificContent"`
+	Visibility struct {
+		MemberNetworkVisibility string `json:"com.linkedin.ugc.MemberNetworkVisibility"`
+	} `json:"visibility"`
+}
+
+// ShareRepository implements share.Repository using the UGC API
+type ShareRepository struct {
+	identity entity.IdentityProvider
+	client   *http.Client
+}
+
+func NewShareRepository(identity entity.IdentityProvider) *ShareRepository {
+	return &ShareRepository{
+		identity: identity,
+		client:   &http.Client{},
+	}
+}
+
+func (l *ShareRepository) createNewPost(article entity.ArticleShare) *LinkedinUGCSharePost {
+	// Create share content information
+	shareContent := LinkedinUGCShareContent{}
+	shareContent.ShareCommentary.Text = article.Comment
+	shareContent.ShareMediaCategory = "ARTICLE"
+	shareContent.Media = []LinkedinUGCShareMedia{
+		LinkedinUGCShareMedia{
+			Status: "READY",
+			Description: struct {
+				Text string "json:\"text\""
+			}{article.Title},
+			OriginalURL: article.URL,
+			Title: struct {
+				Text string "json:\"text\""
+			}{article.Title},
+		},
+	}
+
+	// Create UGC share post
+	sharePost := LinkedinUGCSharePost{}
+	sharePost.Author = fmt.Sprintf("urn:li:person:%s", l.identity.UserID)
+	sharePost.LifecycleState = "PUBLISHED"
+	sharePost.SpecificContent.ShareContent = shareContent
+	sharePost.Visibility = struct {
+		MemberNetworkVisibility string `json:"com.linkedin.ugc.MemberNetworkVisibility"`
+	}{"PUBLIC"}
+
+	return &sharePost
+}
+
+// checkLength checks the length of the share commentary
+func checkLength(article entity.ArticleShare) error {
+	if n := utf8.RuneCountInString(article.Comment); n > linkedinMaxCharacters {
+		return fmt.Errorf("Post max characters exceeded: %d (allowed: %d)", n, linkedinMaxCharacters)
+	}
+	return nil
+}
+
+// PreviewArticle returns the UGC post which would be sent
+func (l *ShareRepository) PreviewArticle(ctx context.Context, article entity.ArticleShare) (entity.SharePreview, error) {
+	if err := checkLength(article); err != nil {
+		return entity.SharePreview{}, err
+	}
+	return entity.SharePreview{
+		Text:      article.Comment,
+		MaxLength: linkedinMaxCharacters,
+		Payload:   l.createNewPost(article),
+	}, nil
+}
+
+// ShareArticle creates a new UGC post
+func (l *ShareRepository) ShareArticle(ctx context.Context, article entity.ArticleShare) (entity.ShareResult, error) {
+	if err := checkLength(article); err != nil {
+		return entity.ShareResult{}, err
+	}
+	ugcPost := l.createNewPost(article)
+
+	// Marshalize ugcPost
+	jsonStr, err := json.MarshalIndent(ugcPost, "", "  ")
+	if err != nil {
+		return entity.ShareResult{}, fmt.Errorf("Couldn't marshalize ugcPost: %s\n", err)
+	}
+
+	// Create new HTTP request
+	req, err := l.newRequest(ctx, "POST", linkedinUGCAPI, bytes.NewBuffer(jsonStr))
+	if err != nil {
+		return entity.ShareResult{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send request
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return entity.ShareResult{}, fmt.Errorf("Couldn't send ugcPost: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return entity.ShareResult{}, fmt.Errorf("Couldn't create ugcPost: %s (%s)", resp.Status, body)
+	}
+
+	// The URN of the new post is returned as a header
+	postID := resp.Header.Get("X-RestLi-Id")
+	return entity.ShareResult{
+		PostID:  postID,
+		PostURL: fmt.Sprintf("https://www.linkedin.com/feed/update/%s", postID),
+	}, nil
+}
+
+// DeletePost deletes a UGC post
+func (l *ShareRepository) DeletePost(ctx context.Context, postID string) error {
+	req, err := l.newRequest(ctx, "DELETE", fmt.Sprintf("%s/%s", linkedinUGCAPI, url.PathEscape(postID)), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Couldn't delete ugcPost: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Couldn't delete ugcPost: %s", resp.Status)
+	}
+	return nil
+}
+
+// linkedinSocialActions is the summary of likes and comments of a post
+//
+// Check out https://docs.microsoft.com/en-us/linkedin/marketing/integrations/community-management/shares/network-update-social-actions
+type linkedinSocialActions struct {
+	LikesSummary struct {
+		TotalLikes int `json:"totalLikes"`
+	} `json:"likesSummary"`
+	CommentsSummary struct {
+		AggregatedTotalComments int `json:"aggregatedTotalComments"`
+	} `json:"commentsSummary"`
+}
+
+// GetMetrics fetches likes and comments of a UGC post
+func (l *ShareRepository) GetMetrics(ctx context.Context, postID string) (entity.PostMetrics, error) {
+	req, err := l.newRequest(ctx, "GET", fmt.Sprintf("%s/%s", linkedinSocialActionsAPI, url.PathEscape(postID)), nil)
+	if err != nil {
+		return entity.PostMetrics{}, err
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return entity.PostMetrics{}, fmt.Errorf("Couldn't fetch social actions: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return entity.PostMetrics{}, fmt.Errorf("Couldn't fetch social actions: %s", resp.Status)
+	}
+
+	var actions linkedinSocialActions
+	if err := json.NewDecoder(resp.Body).Decode(&actions); err != nil {
+		return entity.PostMetrics{}, fmt.Errorf("Couldn't unmarshalize social actions: %s", err)
+	}
+	return entity.PostMetrics{
+		Likes:    actions.LikesSummary.TotalLikes,
+		Comments: actions.CommentsSummary.AggregatedTotalComments,
+	}, nil
+}
+
+// newRequest creates an authenticated request against the LinkedIn API
+func (l *ShareRepository) newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't create request: %s", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", l.identity.AccessToken))
+	req.Header.Set("X-Restli-Protocol-Version", "2.0.0")
+	return req, nil
+}
@@ -0,0 +1,94 @@
+package mastodon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// client calls the REST API of a Mastodon instance
+// (https://docs.joinmastodon.org/methods/)
+type client struct {
+	instance string
+	token    string
+	http     *http.Client
+}
+
+func newClient(instance, token string) *client {
+	return &client{
+		instance: strings.TrimSuffix(instance, "/"),
+		token:    token,
+		http:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// do sends a request to the instance. path segments must already be
+// escaped.
+func (c *client) do(ctx context.Context, method, path string, payload interface{}, result interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("Couldn't marshal request: %s", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	// Create new HTTP request
+	req, err := http.NewRequestWithContext(ctx, method, c.instance+path, body)
+	if err != nil {
+		return fmt.Errorf("Couldn't create request: %s", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Send request
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("Couldn't call Mastodon: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("Mastodon rate limit exceeded (reset at %s)", resp.Header.Get("X-RateLimit-Reset"))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&e); err != nil || e.Error == "" {
+			return fmt.Errorf("Mastodon returned %s", resp.Status)
+		}
+		return fmt.Errorf("Mastodon returned %s: %s", resp.Status, e.Error)
+	}
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("Couldn't unmarshalize response: %s", err)
+		}
+	}
+	return nil
+}
+
+// Account is the account the access token belongs to
+type Account struct {
+	ID          string `json:"id"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	Avatar      string `json:"avatar"`
+}
+
+// Status is a published status
+type Status struct {
+	ID              string `json:"id"`
+	URL             string `json:"url"`
+	RepliesCount    int    `json:"replies_count"`
+	ReblogsCount    int    `json:"reblogs_count"`
+	FavouritesCount int    `json:"favourites_count"`
+}
@@ -0,0 +1,58 @@
+// Package mastodon shares articles as Mastodon statuses. The instance and
+// the access token are kept in the identity store.
+package mastodon
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/provider"
+	"github.com/dorneanu/gocial/internal/share"
+)
+
+// Provider returns the registry entry of Mastodon
+func Provider(conf config.MastodonConfig) provider.Provider {
+	return provider.Provider{
+		ProviderInfo: entity.ProviderInfo{
+			Name:        "mastodon",
+			DisplayName: "Mastodon",
+			Capabilities: entity.ProviderCapabilities{
+				MaxLength: conf.MaxCharacters,
+				Edit:      true,
+				Delete:    true,
+				Metrics:   true,
+			},
+		},
+		Connect: connect,
+		NewRepository: func(client config.ProviderConfig, id entity.IdentityProvider) (share.Repository, error) {
+			return NewShareRepository(conf, id), nil
+		},
+	}
+}
+
+// connect checks the access token (created under Preferences >
+// Development of the instance) and stores the account it belongs to
+func connect(ctx context.Context, id entity.IdentityProvider) (entity.IdentityProvider, error) {
+	if id.Endpoint == "" || id.AccessToken == "" {
+		return id, fmt.Errorf("Instance and access token are required")
+	}
+	instance, err := url.Parse(id.Endpoint)
+	if err != nil || instance.Host == "" {
+		return id, fmt.Errorf("Invalid instance URL: %s", id.Endpoint)
+	}
+	c := newClient(id.Endpoint, id.AccessToken)
+
+	var account Account
+	if err := c.do(ctx, http.MethodGet, "/api/v1/accounts/verify_credentials", nil, &account); err != nil {
+		return id, err
+	}
+	id.UserID = account.ID
+	id.UserName = fmt.Sprintf("@%s@%s", account.Username, instance.Host)
+	id.UserDescription = account.DisplayName
+	id.UserAvatarURL = account.Avatar
+	return id, nil
+}
@@ -0,0 +1,156 @@
+package mastodon
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+const testToken = "test-token"
+
+// newFakeInstance returns a Mastodon instance knowing a single status
+func newFakeInstance(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/accounts/verify_credentials", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Account{ID: "42", Username: "gocial", DisplayName: "Gocial", Avatar: "https://example.com/a.png"})
+	})
+	mux.HandleFunc("/api/v1/statuses", func(w http.ResponseWriter, r *http.Request) {
+		var params StatusParams
+		if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&params) != nil {
+			http.Error(w, `{"error": "bad request"}`, http.StatusBadRequest)
+			return
+		}
+		if strings.Contains(params.Status, "limit") {
+			w.Header().Set("X-RateLimit-Reset", "2026-10-19T12:00:00Z")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if params.Visibility != "unlisted" {
+			t.Errorf("visibility = %q; want unlisted", params.Visibility)
+		}
+		json.NewEncoder(w).Encode(Status{ID: "1001", URL: "https://example.social/@gocial/1001"})
+	})
+	mux.HandleFunc("/api/v1/statuses/1001", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			var params StatusParams
+			json.NewDecoder(r.Body).Decode(&params)
+			if params.Status != "Fixed typo\n\nhttps://example.com/post" || params.Visibility != "" {
+				http.Error(w, `{"error": "unexpected edit"}`, http.StatusUnprocessableEntity)
+				return
+			}
+		}
+		json.NewEncoder(w).Encode(Status{ID: "1001", FavouritesCount: 3, ReblogsCount: 2, RepliesCount: 1})
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": "The access token is invalid"}`))
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestConnect(t *testing.T) {
+	srv := newFakeInstance(t)
+
+	id, err := connect(context.Background(), entity.IdentityProvider{Provider: "mastodon", Endpoint: srv.URL, AccessToken: testToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := strings.TrimPrefix(srv.URL, "http://")
+	if id.UserID != "42" || id.UserName != "@gocial@"+host || id.UserDescription != "Gocial" {
+		t.Errorf("identity = %+v", id)
+	}
+
+	_, err = connect(context.Background(), entity.IdentityProvider{Endpoint: srv.URL, AccessToken: "wrong"})
+	if err == nil || !strings.Contains(err.Error(), "The access token is invalid") {
+		t.Errorf("connect with wrong token: %v", err)
+	}
+}
+
+func TestShareRepository(t *testing.T) {
+	srv := newFakeInstance(t)
+	conf := config.MastodonConfig{Visibility: "unlisted", MaxCharacters: 500}
+	repo := NewShareRepository(conf, entity.IdentityProvider{Endpoint: srv.URL, AccessToken: testToken})
+	ctx := context.Background()
+
+	result, err := repo.ShareArticle(ctx, entity.ArticleShare{URL: "https://example.com/post", Comment: "New post"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.PostID != "1001" || result.PostURL != "https://example.social/@gocial/1001" {
+		t.Errorf("result = %+v", result)
+	}
+
+	m, err := repo.GetMetrics(ctx, result.PostID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != (entity.PostMetrics{Likes: 3, Reposts: 2, Comments: 1}) {
+		t.Errorf("metrics = %+v", m)
+	}
+
+	_, err = repo.ShareArticle(ctx, entity.ArticleShare{URL: "https://example.com/post", Comment: "limit"})
+	if err == nil || !strings.Contains(err.Error(), "rate limit exceeded (reset at 2026-10-19T12:00:00Z)") {
+		t.Errorf("rate limited share: %v", err)
+	}
+	if _, err := repo.GetMetrics(ctx, "missing"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("metrics of missing status: %v", err)
+	}
+}
+
+func TestEditAndDeletePost(t *testing.T) {
+	srv := newFakeInstance(t)
+	conf := config.MastodonConfig{Visibility: "unlisted", MaxCharacters: 500}
+	repo := NewShareRepository(conf, entity.IdentityProvider{Endpoint: srv.URL, AccessToken: testToken})
+	ctx := context.Background()
+
+	if err := repo.EditPost(ctx, "1001", entity.ArticleShare{URL: "https://example.com/post", Comment: "Fixed typo"}); err != nil {
+		t.Errorf("EditPost: %s", err)
+	}
+	err := repo.EditPost(ctx, "1001", entity.ArticleShare{URL: "https://example.com/post", Comment: "Other"})
+	if err == nil || !strings.Contains(err.Error(), "unexpected edit") {
+		t.Errorf("rejected edit: %v", err)
+	}
+	if err := repo.DeletePost(ctx, "1001"); err != nil {
+		t.Errorf("DeletePost: %s", err)
+	}
+	if err := repo.DeletePost(ctx, "1002"); err == nil {
+		t.Error("DeletePost of a missing status succeeded")
+	}
+}
+
+func TestComposeStatusCountsURLs(t *testing.T) {
+	repo := NewShareRepository(config.MastodonConfig{MaxCharacters: 50}, entity.IdentityProvider{})
+	longURL := "https://example.com/" + strings.Repeat("a", 200)
+
+	// 25 characters + 2 + 23 for the URL
+	params, err := repo.composeStatus(entity.ArticleShare{URL: longURL, Comment: strings.Repeat("ü", 25)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.Status != strings.Repeat("ü", 25)+"\n\n"+longURL {
+		t.Errorf("status = %q", params.Status)
+	}
+
+	_, err = repo.composeStatus(entity.ArticleShare{URL: longURL, Comment: strings.Repeat("ü", 26)})
+	if err == nil || !strings.Contains(err.Error(), "51 (allowed: 50)") {
+		t.Errorf("too long status: %v", err)
+	}
+
+	params, _ = repo.composeStatus(entity.ArticleShare{URL: "https://example.com", Title: "Title"})
+	if params.Status != "Title\n\nhttps://example.com" {
+		t.Errorf("status without comment = %q", params.Status)
+	}
+}
@@ -0,0 +1,123 @@
+package mastodon
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"unicode/utf8"
+
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+// urlLength is the length Mastodon counts for every URL regardless of its
+// actual length
+const urlLength = 23
+
+// StatusParams are the parameters of a new status
+type StatusParams struct {
+	Status     string `json:"status"`
+	Visibility string `json:"visibility,omitempty"`
+}
+
+// ShareRepository publishes statuses with the account stored in the
+// identity. The identity holds the instance URL as endpoint and the access
+// token.
+type ShareRepository struct {
+	conf   config.MastodonConfig
+	client *client
+}
+
+func NewShareRepository(conf config.MastodonConfig, identity entity.IdentityProvider) *ShareRepository {
+	return &ShareRepository{
+		conf:   conf,
+		client: newClient(identity.Endpoint, identity.AccessToken),
+	}
+}
+
+// composeStatus creates the status (comment or title followed by the URL)
+// and checks its length like Mastodon does
+func (m *ShareRepository) composeStatus(article entity.ArticleShare) (StatusParams, error) {
+	text := article.Comment
+	if text == "" {
+		text = article.Title
+	}
+
+	status := text
+	n := utf8.RuneCountInString(text)
+	if article.URL != "" {
+		if status != "" {
+			status += "\n\n"
+			n += 2
+		}
+		status += article.URL
+		n += urlLength
+	}
+	if m.conf.MaxCharacters > 0 && n > m.conf.MaxCharacters {
+		return StatusParams{}, fmt.Errorf("Post max characters exceeded: %d (allowed: %d)", n, m.conf.MaxCharacters)
+	}
+	return StatusParams{Status: status, Visibility: m.conf.Visibility}, nil
+}
+
+// PreviewArticle returns the status which would be published
+func (m *ShareRepository) PreviewArticle(ctx context.Context, article entity.ArticleShare) (entity.SharePreview, error) {
+	params, err := m.composeStatus(article)
+	if err != nil {
+		return entity.SharePreview{}, err
+	}
+	return entity.SharePreview{
+		Text:      params.Status,
+		MaxLength: m.conf.MaxCharacters,
+		Payload:   params,
+	}, nil
+}
+
+// ShareArticle publishes a new status
+func (m *ShareRepository) ShareArticle(ctx context.Context, article entity.ArticleShare) (entity.ShareResult, error) {
+	params, err := m.composeStatus(article)
+	if err != nil {
+		return entity.ShareResult{}, err
+	}
+
+	var status Status
+	if err := m.client.do(ctx, http.MethodPost, "/api/v1/statuses", params, &status); err != nil {
+		return entity.ShareResult{}, fmt.Errorf("Couldn't publish status: %s", err)
+	}
+	return entity.ShareResult{PostID: status.ID, PostURL: status.URL}, nil
+}
+
+// GetMetrics fetches favourites, reblogs and replies of a status
+func (m *ShareRepository) GetMetrics(ctx context.Context, postID string) (entity.PostMetrics, error) {
+	var status Status
+	if err := m.client.do(ctx, http.MethodGet, "/api/v1/statuses/"+url.PathEscape(postID), nil, &status); err != nil {
+		return entity.PostMetrics{}, fmt.Errorf("Couldn't fetch status: %s", err)
+	}
+	return entity.PostMetrics{
+		Likes:    status.FavouritesCount,
+		Reposts:  status.ReblogsCount,
+		Comments: status.RepliesCount,
+	}, nil
+}
+
+// EditPost replaces the text of a published status
+func (m *ShareRepository) EditPost(ctx context.Context, postID string, article entity.ArticleShare) error {
+	params, err := m.composeStatus(article)
+	if err != nil {
+		return err
+	}
+	// The visibility of a status can't be changed
+	params.Visibility = ""
+	if err := m.client.do(ctx, http.MethodPut, "/api/v1/statuses/"+url.PathEscape(postID), params, nil); err != nil {
+		return fmt.Errorf("Couldn't edit status: %s", err)
+	}
+	return nil
+}
+
+// DeletePost deletes a published status
+func (m *ShareRepository) DeletePost(ctx context.Context, postID string) error {
+	if err := m.client.do(ctx, http.MethodDelete, "/api/v1/statuses/"+url.PathEscape(postID), nil, nil); err != nil {
+		return fmt.Errorf("Couldn't delete status: %s", err)
+	}
+	return nil
+}
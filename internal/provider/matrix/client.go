@@ -0,0 +1,102 @@
+package matrix
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// client calls the Matrix client-server API
+// (https://spec.matrix.org/latest/client-server-api/)
+type client struct {
+	homeserver string
+	token      string
+	http       *http.Client
+}
+
+func newClient(homeserver, token string) *client {
+	return &client{
+		homeserver: strings.TrimSuffix(homeserver, "/"),
+		token:      token,
+		http:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// apiError is returned by the homeserver for failed requests
+type apiError struct {
+	ErrCode      string `json:"errcode"`
+	Error        string `json:"error"`
+	RetryAfterMs int    `json:"retry_after_ms"`
+}
+
+// do sends a request to the homeserver. path segments must already be
+// escaped.
+func (c *client) do(ctx context.Context, method, path string, payload interface{}, result interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("Couldn't marshal request: %s", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	// Create new HTTP request
+	req, err := http.NewRequestWithContext(ctx, method, c.homeserver+"/_matrix/client/v3"+path, body)
+	if err != nil {
+		return fmt.Errorf("Couldn't create request: %s", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Send request
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("Couldn't call homeserver: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var e apiError
+		if err := json.NewDecoder(resp.Body).Decode(&e); err != nil || e.ErrCode == "" {
+			return fmt.Errorf("Homeserver returned %s", resp.Status)
+		}
+		if e.ErrCode == "M_LIMIT_EXCEEDED" {
+			return fmt.Errorf("Matrix rate limit exceeded (retry after %dms)", e.RetryAfterMs)
+		}
+		return fmt.Errorf("Homeserver returned %s: %s", e.ErrCode, e.Error)
+	}
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("Couldn't unmarshalize response: %s", err)
+		}
+	}
+	return nil
+}
+
+// txnID returns a new transaction ID. Homeservers use it to deduplicate
+// retried requests.
+func txnID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return "gocial-" + hex.EncodeToString(b)
+}
+
+// roomPath returns the escaped path of a room endpoint
+func roomPath(roomID string, segments ...string) string {
+	path := "/rooms/" + url.PathEscape(roomID)
+	for _, s := range segments {
+		path += "/" + url.PathEscape(s)
+	}
+	return path
+}
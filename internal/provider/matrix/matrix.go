@@ -0,0 +1,62 @@
+// Package matrix shares articles to Matrix rooms. The homeserver, access
+// token and room are kept in the identity store.
+package matrix
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/provider"
+	"github.com/dorneanu/gocial/internal/share"
+)
+
+// Provider returns the registry entry of Matrix
+func Provider(conf config.MatrixConfig) provider.Provider {
+	return provider.Provider{
+		ProviderInfo: entity.ProviderInfo{
+			Name:        "matrix",
+			DisplayName: "Matrix",
+			Capabilities: entity.ProviderCapabilities{
+				Edit:   true,
+				Delete: true,
+			},
+		},
+		Connect: connect,
+		NewRepository: func(client config.ProviderConfig, id entity.IdentityProvider) (share.Repository, error) {
+			return NewShareRepository(conf, id), nil
+		},
+	}
+}
+
+// connect checks the access token and joins the room (an ID or alias). The
+// room ID is stored instead of the alias.
+func connect(ctx context.Context, id entity.IdentityProvider) (entity.IdentityProvider, error) {
+	if id.Endpoint == "" || id.AccessToken == "" || id.UserID == "" {
+		return id, fmt.Errorf("Homeserver, access token and room are required")
+	}
+	c := newClient(id.Endpoint, id.AccessToken)
+
+	var whoami struct {
+		UserID string `json:"user_id"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/account/whoami", nil, &whoami); err != nil {
+		return id, err
+	}
+
+	// Joining a room the user is already member of has no effect
+	var joined struct {
+		RoomID string `json:"room_id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/join/"+url.PathEscape(id.UserID), struct{}{}, &joined); err != nil {
+		return id, fmt.Errorf("Couldn't join room %s: %s", id.UserID, err)
+	}
+
+	id.UserDescription = id.UserID
+	id.UserID = joined.RoomID
+	id.UserName = whoami.UserID
+	return id, nil
+}
@@ -0,0 +1,104 @@
+package matrix
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+const (
+	testToken  = "syt_test_token"
+	testRoomID = "!room:example.org"
+)
+
+// newFakeHomeserver returns a homeserver accepting m.room.message events
+// for a single room
+func newFakeHomeserver(t *testing.T) *httptest.Server {
+	prefix := "/_matrix/client/v3/rooms/%21room:example.org/send/m.room.message/"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("Authorization") != "Bearer "+testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"errcode": "M_UNKNOWN_TOKEN", "error": "Invalid access token passed."}`))
+			return
+		}
+		path := r.URL.EscapedPath()
+		if r.Method != http.MethodPut || !strings.HasPrefix(path, prefix) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errcode": "M_FORBIDDEN", "error": "User not in room"}`))
+			return
+		}
+		if !strings.HasPrefix(strings.TrimPrefix(path, prefix), "gocial-") {
+			t.Errorf("transaction ID of %s", path)
+		}
+
+		var msg Message
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if strings.Contains(msg.Body, "limit") {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"errcode": "M_LIMIT_EXCEEDED", "error": "Too many requests", "retry_after_ms": 2000}`))
+			return
+		}
+		if msg.MsgType != "m.notice" || msg.Format != "org.matrix.custom.html" {
+			t.Errorf("message = %+v", msg)
+		}
+		w.Write([]byte(`{"event_id": "$event1"}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestShareArticle(t *testing.T) {
+	srv := newFakeHomeserver(t)
+	conf := config.MatrixConfig{MsgType: "m.notice"}
+	repo := NewShareRepository(conf, entity.IdentityProvider{Endpoint: srv.URL, AccessToken: testToken, UserID: testRoomID})
+	ctx := context.Background()
+
+	result, err := repo.ShareArticle(ctx, entity.ArticleShare{URL: "https://example.com/post", Title: "Post", Comment: "New post"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.PostID != "$event1" || result.PostURL != "https://matrix.to/#/%21room:example.org/$event1" {
+		t.Errorf("result = %+v", result)
+	}
+
+	_, err = repo.ShareArticle(ctx, entity.ArticleShare{URL: "https://example.com/post", Title: "Post", Comment: "limit"})
+	if err == nil || err.Error() != "Matrix rate limit exceeded (retry after 2000ms)" {
+		t.Errorf("rate limited share: %v", err)
+	}
+
+	other := NewShareRepository(conf, entity.IdentityProvider{Endpoint: srv.URL, AccessToken: testToken, UserID: "!other:example.org"})
+	_, err = other.ShareArticle(ctx, entity.ArticleShare{URL: "https://example.com/post", Title: "Post"})
+	if err == nil || err.Error() != "Homeserver returned M_FORBIDDEN: User not in room" {
+		t.Errorf("share to other room: %v", err)
+	}
+
+	wrongToken := NewShareRepository(conf, entity.IdentityProvider{Endpoint: srv.URL, AccessToken: "wrong", UserID: testRoomID})
+	_, err = wrongToken.ShareArticle(ctx, entity.ArticleShare{URL: "https://example.com/post", Title: "Post"})
+	if err == nil || !strings.Contains(err.Error(), "M_UNKNOWN_TOKEN") {
+		t.Errorf("share with wrong token: %v", err)
+	}
+}
+
+func TestDoWithoutErrorCode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("<html>Bad Gateway</html>"))
+	}))
+	defer srv.Close()
+
+	err := newClient(srv.URL, testToken).do(context.Background(), http.MethodGet, "/account/whoami", nil, nil)
+	if err == nil || err.Error() != "Homeserver returned 502 Bad Gateway" {
+		t.Errorf("error = %v", err)
+	}
+}
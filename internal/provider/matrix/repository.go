@@ -0,0 +1,121 @@
+package matrix
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+// Message is the content of an m.room.message event
+type Message struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format,omitempty"`
+	FormattedBody string `json:"formatted_body,omitempty"`
+	// NewContent and RelatesTo are set for edits
+	NewContent *Message   `json:"m.new_content,omitempty"`
+	RelatesTo  *RelatesTo `json:"m.relates_to,omitempty"`
+}
+
+type RelatesTo struct {
+	RelType string `json:"rel_type"`
+	EventID string `json:"event_id"`
+}
+
+// ShareRepository sends messages to the room stored in the identity. The
+// identity holds the homeserver as endpoint, the access token and the room
+// ID as user ID.
+type ShareRepository struct {
+	conf   config.MatrixConfig
+	roomID string
+	client *client
+}
+
+func NewShareRepository(conf config.MatrixConfig, identity entity.IdentityProvider) *ShareRepository {
+	return &ShareRepository{
+		conf:   conf,
+		roomID: identity.UserID,
+		client: newClient(identity.Endpoint, identity.AccessToken),
+	}
+}
+
+// composeMessage formats an article as plain text and HTML: the linked
+// title followed by the comment
+func (m *ShareRepository) composeMessage(article entity.ArticleShare) Message {
+	title := article.Title
+	if title == "" {
+		title = article.URL
+	}
+
+	body := fmt.Sprintf("%s\n%s", title, article.URL)
+	formatted := fmt.Sprintf("<p><a href=\"%s\"><strong>%s</strong></a></p>", html.EscapeString(article.URL), html.EscapeString(title))
+	if article.Comment != "" {
+		body += "\n\n" + article.Comment
+		formatted += "<p>" + strings.ReplaceAll(html.EscapeString(article.Comment), "\n", "<br>") + "</p>"
+	}
+	return Message{
+		MsgType:       m.conf.MsgType,
+		Body:          body,
+		Format:        "org.matrix.custom.html",
+		FormattedBody: formatted,
+	}
+}
+
+// PreviewArticle returns the event content which would be sent
+func (m *ShareRepository) PreviewArticle(ctx context.Context, article entity.ArticleShare) (entity.SharePreview, error) {
+	msg := m.composeMessage(article)
+	return entity.SharePreview{
+		Text:    msg.Body,
+		Payload: msg,
+	}, nil
+}
+
+// send sends an m.room.message event and returns its ID
+func (m *ShareRepository) send(ctx context.Context, msg Message) (string, error) {
+	var resp struct {
+		EventID string `json:"event_id"`
+	}
+	path := roomPath(m.roomID, "send", "m.room.message", txnID())
+	if err := m.client.do(ctx, http.MethodPut, path, msg, &resp); err != nil {
+		return "", err
+	}
+	return resp.EventID, nil
+}
+
+// ShareArticle sends the article to the room
+func (m *ShareRepository) ShareArticle(ctx context.Context, article entity.ArticleShare) (entity.ShareResult, error) {
+	eventID, err := m.send(ctx, m.composeMessage(article))
+	if err != nil {
+		return entity.ShareResult{}, err
+	}
+	return entity.ShareResult{
+		PostID:  eventID,
+		PostURL: fmt.Sprintf("https://matrix.to/#/%s/%s", url.PathEscape(m.roomID), url.PathEscape(eventID)),
+	}, nil
+}
+
+// EditPost replaces a sent message. Clients without support for edits show
+// the fallback body prefixed with "*".
+func (m *ShareRepository) EditPost(ctx context.Context, postID string, article entity.ArticleShare) error {
+	content := m.composeMessage(article)
+	edit := content
+	edit.Body = "* " + content.Body
+	edit.FormattedBody = "* " + content.FormattedBody
+	edit.NewContent = &content
+	edit.RelatesTo = &RelatesTo{RelType: "m.replace", EventID: postID}
+
+	_, err := m.send(ctx, edit)
+	return err
+}
+
+// DeletePost redacts a sent message
+func (m *ShareRepository) DeletePost(ctx context.Context, postID string) error {
+	path := roomPath(m.roomID, "redact", postID, txnID())
+	return m.client.do(ctx, http.MethodPut, path, map[string]string{"reason": "Deleted via gocial"}, nil)
+}
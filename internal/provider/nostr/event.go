@@ -0,0 +1,110 @@
+package nostr
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+// Event kinds (NIP-01 and NIP-09)
+const (
+	KindTextNote = 1
+	KindDeletion = 5
+)
+
+// Event is a signed Nostr event
+type Event struct {
+	ID        string     `json:"id"`
+	PubKey    string     `json:"pubkey"`
+	CreatedAt int64      `json:"created_at"`
+	Kind      int        `json:"kind"`
+	Tags      [][]string `json:"tags"`
+	Content   string     `json:"content"`
+	Sig       string     `json:"sig"`
+}
+
+// Sign sets the public key, ID and signature of the event. key is the hex
+// private key.
+func (e *Event) Sign(key string) error {
+	b, err := hex.DecodeString(key)
+	if err != nil {
+		return fmt.Errorf("Invalid private key: %s", err)
+	}
+	priv, _ := btcec.PrivKeyFromBytes(b)
+
+	e.PubKey = hex.EncodeToString(schnorr.SerializePubKey(priv.PubKey()))
+	e.Content = strings.ToValidUTF8(e.Content, "�")
+	if e.Tags == nil {
+		e.Tags = [][]string{}
+	}
+
+	hash := sha256.Sum256(e.serialize())
+	sig, err := schnorr.Sign(priv, hash[:])
+	if err != nil {
+		return fmt.Errorf("Couldn't sign event: %s", err)
+	}
+	e.ID = hex.EncodeToString(hash[:])
+	e.Sig = hex.EncodeToString(sig.Serialize())
+	return nil
+}
+
+// serialize returns the data the event ID is the hash of:
+// [0, pubkey, created_at, kind, tags, content]
+func (e *Event) serialize() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "[0,%s,%d,%d,[", quote(e.PubKey), e.CreatedAt, e.Kind)
+	for i, tag := range e.Tags {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('[')
+		for j, v := range tag {
+			if j > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(quote(v))
+		}
+		b.WriteByte(']')
+	}
+	fmt.Fprintf(&b, "],%s]", quote(e.Content))
+	return b.Bytes()
+}
+
+// quote escapes a string as required by NIP-01. Unlike encoding/json it
+// leaves HTML characters and other Unicode characters alone.
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		default:
+			if r < 0x20 {
+				b.WriteString(`\u` + strconv.FormatInt(int64(r)+0x10000, 16)[1:])
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
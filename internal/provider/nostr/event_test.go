@@ -0,0 +1,93 @@
+package nostr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+func TestSerialize(t *testing.T) {
+	e := Event{
+		PubKey:    "79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+		CreatedAt: 1700000000,
+		Kind:      KindTextNote,
+		Tags:      [][]string{{"r", "https://example.com/?a=1&b=<2>"}, {"t", "go"}},
+		Content:   "Title \"quoted\"\nback\\slash\ttab é 🚀 <html> \x01",
+	}
+	want := `[0,"79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",1700000000,1,` +
+		`[["r","https://example.com/?a=1&b=<2>"],["t","go"]],` +
+		`"Title \"quoted\"\nback\\slash\ttab é 🚀 <html> \u0001"]`
+	if got := string(e.serialize()); got != want {
+		t.Errorf("serialize() =\n%s\nwant\n%s", got, want)
+	}
+
+	// The serialization is valid JSON with the same values
+	var decoded []interface{}
+	if err := json.Unmarshal(e.serialize(), &decoded); err != nil {
+		t.Fatalf("Serialization is no valid JSON: %s", err)
+	}
+	if decoded[5] != e.Content {
+		t.Errorf("Content = %q, want %q", decoded[5], e.Content)
+	}
+
+	empty := Event{PubKey: "ab", Kind: KindDeletion, Tags: [][]string{}}
+	if got := string(empty.serialize()); got != `[0,"ab",0,5,[],""]` {
+		t.Errorf("serialize() = %s", got)
+	}
+}
+
+func TestSign(t *testing.T) {
+	const key = "0000000000000000000000000000000000000000000000000000000000000001"
+	e := Event{CreatedAt: 1700000000, Kind: KindTextNote, Content: "Hello"}
+	if err := e.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+
+	// The public key of 1 is the generator point
+	if e.PubKey != "79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798" {
+		t.Errorf("PubKey = %s", e.PubKey)
+	}
+	if e.Tags == nil {
+		t.Error("Tags must be an empty list, not null")
+	}
+
+	hash := sha256.Sum256(e.serialize())
+	if e.ID != hex.EncodeToString(hash[:]) {
+		t.Errorf("ID %s is not the hash of the serialization", e.ID)
+	}
+	if !verify(t, e) {
+		t.Error("Signature doesn't verify")
+	}
+
+	// Changing the event invalidates the signature
+	e.Content = "Hello!"
+	hash = sha256.Sum256(e.serialize())
+	e.ID = hex.EncodeToString(hash[:])
+	if verify(t, e) {
+		t.Error("Signature verifies for modified content")
+	}
+
+	if err := (&Event{}).Sign("nothex"); err == nil {
+		t.Error("Sign with invalid key didn't fail")
+	}
+}
+
+// verify checks the signature of e
+func verify(t *testing.T, e Event) bool {
+	t.Helper()
+	pubKey, _ := hex.DecodeString(e.PubKey)
+	pub, err := schnorr.ParsePubKey(pubKey)
+	if err != nil {
+		t.Fatalf("Invalid public key: %s", err)
+	}
+	sigBytes, _ := hex.DecodeString(e.Sig)
+	sig, err := schnorr.ParseSignature(sigBytes)
+	if err != nil {
+		t.Fatalf("Invalid signature: %s", err)
+	}
+	id, _ := hex.DecodeString(e.ID)
+	return sig.Verify(id, pub)
+}
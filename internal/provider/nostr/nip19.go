@@ -0,0 +1,132 @@
+package nostr
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// NIP-19 encodes keys and IDs as bech32 (BIP-173) strings like "nsec1..."
+
+const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+// Encode returns the bech32 string of data with the prefix hrp (e.g. "npub")
+func Encode(hrp string, data []byte) (string, error) {
+	values, err := convertBits(data, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	checksum := polymod(append(hrpExpand(hrp), append(values, 0, 0, 0, 0, 0, 0)...)) ^ 1
+
+	var b strings.Builder
+	b.WriteString(hrp + "1")
+	for _, v := range values {
+		b.WriteByte(charset[v])
+	}
+	for i := 0; i < 6; i++ {
+		b.WriteByte(charset[(checksum>>(5*(5-i)))&31])
+	}
+	return b.String(), nil
+}
+
+// Decode returns the prefix and the data of a bech32 string
+func Decode(s string) (string, []byte, error) {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, fmt.Errorf("Mixed case in bech32 string")
+	}
+	s = strings.ToLower(s)
+
+	pos := strings.LastIndex(s, "1")
+	if pos < 1 || pos+7 > len(s) {
+		return "", nil, fmt.Errorf("Invalid bech32 string")
+	}
+	hrp := s[:pos]
+	values := make([]byte, 0, len(s)-pos-1)
+	for _, c := range s[pos+1:] {
+		v := strings.IndexRune(charset, c)
+		if v < 0 {
+			return "", nil, fmt.Errorf("Invalid bech32 character %q", c)
+		}
+		values = append(values, byte(v))
+	}
+	if polymod(append(hrpExpand(hrp), values...)) != 1 {
+		return "", nil, fmt.Errorf("Invalid bech32 checksum")
+	}
+
+	data, err := convertBits(values[:len(values)-6], 5, 8, false)
+	if err != nil {
+		return "", nil, err
+	}
+	return hrp, data, nil
+}
+
+// decodeKey returns the hex private key of an "nsec1..." or hex string
+func decodeKey(key string) (string, error) {
+	key = strings.TrimSpace(key)
+	if !strings.HasPrefix(key, "nsec1") {
+		if b, err := hex.DecodeString(key); err != nil || len(b) != 32 {
+			return "", fmt.Errorf("Key is neither an nsec nor a hex private key")
+		}
+		return strings.ToLower(key), nil
+	}
+
+	hrp, data, err := Decode(key)
+	if err != nil {
+		return "", err
+	}
+	if hrp != "nsec" || len(data) != 32 {
+		return "", fmt.Errorf("Invalid nsec")
+	}
+	return hex.EncodeToString(data), nil
+}
+
+func polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func hrpExpand(hrp string) []byte {
+	out := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+// convertBits regroups data of from bits per value into values of to bits
+func convertBits(data []byte, from, to uint, pad bool) ([]byte, error) {
+	acc, bits := uint32(0), uint(0)
+	maxv := uint32(1)<<to - 1
+	out := make([]byte, 0, len(data)*int(from)/int(to)+1)
+	for _, v := range data {
+		acc = acc<<from | uint32(v)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(to-bits)&maxv))
+		}
+	} else if bits >= from || acc<<(to-bits)&maxv != 0 {
+		return nil, fmt.Errorf("Invalid padding in bech32 data")
+	}
+	return out, nil
+}
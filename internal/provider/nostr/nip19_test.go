@@ -0,0 +1,86 @@
+package nostr
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// Examples of NIP-19
+func TestNIP19(t *testing.T) {
+	tests := []struct {
+		hrp, hex, bech32 string
+	}{
+		{"npub", "7e7e9c42a91bfef19fa929e5fda1b72e0ebc1a4c1141673e2794234d86addf4e", "npub10elfcs4fr0l0r8af98jlmgdh9c8tcxjvz9qkw038js35mp4dma8qzvjptg"},
+		{"nsec", "67dea2ed018072d675f5415ecfaed7d2597555e202d85b3d65ea4e58d2d92ffa", "nsec1vl029mgpspedva04g90vltkh6fvh240zqtv9k0t9af8935ke9laqsnlfe5"},
+	}
+	for _, tt := range tests {
+		data, _ := hex.DecodeString(tt.hex)
+		got, err := Encode(tt.hrp, data)
+		if err != nil || got != tt.bech32 {
+			t.Errorf("Encode(%s, %s) = %s, %v, want %s", tt.hrp, tt.hex, got, err, tt.bech32)
+		}
+
+		for _, s := range []string{tt.bech32, strings.ToUpper(tt.bech32)} {
+			hrp, data, err := Decode(s)
+			if err != nil || hrp != tt.hrp || hex.EncodeToString(data) != tt.hex {
+				t.Errorf("Decode(%s) = %s, %x, %v", s, hrp, data, err)
+			}
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	valid := "nsec1vl029mgpspedva04g90vltkh6fvh240zqtv9k0t9af8935ke9laqsnlfe5"
+	tests := map[string]string{
+		"mixed case":   "nsec1VL029mgpspedva04g90vltkh6fvh240zqtv9k0t9af8935ke9laqsnlfe5",
+		"checksum":     valid[:len(valid)-1] + "6",
+		"character":    strings.Replace(valid, "v", "b", 1),
+		"no separator": "nsecvl029mgpspedva04g90vltkh6fvh240zqtv9k0t9af8935ke9laqsnlfe5",
+		"too short":    "nsec1qqqqq",
+	}
+	for name, s := range tests {
+		if _, _, err := Decode(s); err == nil {
+			t.Errorf("%s: Decode(%s) didn't fail", name, s)
+		}
+	}
+}
+
+func TestDecodeKey(t *testing.T) {
+	const key = "67dea2ed018072d675f5415ecfaed7d2597555e202d85b3d65ea4e58d2d92ffa"
+	tests := []struct {
+		in   string
+		want string
+		fail bool
+	}{
+		{in: "nsec1vl029mgpspedva04g90vltkh6fvh240zqtv9k0t9af8935ke9laqsnlfe5", want: key},
+		{in: " nsec1vl029mgpspedva04g90vltkh6fvh240zqtv9k0t9af8935ke9laqsnlfe5\n", want: key},
+		{in: key, want: key},
+		{in: strings.ToUpper(key), want: key},
+		{in: "npub10elfcs4fr0l0r8af98jlmgdh9c8tcxjvz9qkw038js35mp4dma8qzvjptg", fail: true},
+		{in: key[:62], fail: true},
+		{in: "not a key", fail: true},
+	}
+	for _, tt := range tests {
+		got, err := decodeKey(tt.in)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("decodeKey(%q) didn't fail", tt.in)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("decodeKey(%q) = %s, %v, want %s", tt.in, got, err, tt.want)
+		}
+	}
+
+	// Round trip of a key through its nsec
+	data, _ := hex.DecodeString(key)
+	nsec, err := Encode("nsec", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := decodeKey(nsec); err != nil || got != key {
+		t.Errorf("decodeKey(Encode(%s)) = %s, %v", key, got, err)
+	}
+}
@@ -0,0 +1,62 @@
+// Package nostr publishes articles as text notes (kind 1) to Nostr relays.
+// The private key is kept in the identity store.
+package nostr
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/provider"
+	"github.com/dorneanu/gocial/internal/share"
+)
+
+// Provider returns the registry entry of Nostr
+func Provider(conf config.NostrConfig) provider.Provider {
+	return provider.Provider{
+		ProviderInfo: entity.ProviderInfo{
+			Name:        "nostr",
+			DisplayName: "Nostr",
+			Capabilities: entity.ProviderCapabilities{
+				Delete: true,
+			},
+		},
+		Connect: connect,
+		NewRepository: func(client config.ProviderConfig, id entity.IdentityProvider) (share.Repository, error) {
+			return NewShareRepository(conf, id), nil
+		},
+	}
+}
+
+// connect imports a private key (nsec or hex). The public key is stored as
+// user ID and its npub as user name.
+func connect(ctx context.Context, id entity.IdentityProvider) (entity.IdentityProvider, error) {
+	key, err := decodeKey(id.AccessToken)
+	if err != nil {
+		return id, err
+	}
+	b, _ := hex.DecodeString(key)
+	priv, _ := btcec.PrivKeyFromBytes(b)
+	if priv.Key.IsZero() {
+		return id, fmt.Errorf("Invalid private key")
+	}
+	pub := schnorr.SerializePubKey(priv.PubKey())
+
+	nsec, err := Encode("nsec", b)
+	if err != nil {
+		return id, err
+	}
+	npub, err := Encode("npub", pub)
+	if err != nil {
+		return id, err
+	}
+
+	id.AccessToken = nsec
+	id.UserID = hex.EncodeToString(pub)
+	id.UserName = npub
+	return id, nil
+}
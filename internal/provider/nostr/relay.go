@@ -0,0 +1,144 @@
+package nostr
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/entity"
+	"golang.org/x/net/websocket"
+)
+
+// origin is sent in the WebSocket handshake
+const origin = "http://localhost/"
+
+// broadcast publishes an event to all relays in parallel. The deliveries
+// are in the order of the relays.
+func broadcast(ctx context.Context, relays []string, event Event, timeout time.Duration) []entity.Delivery {
+	deliveries := make([]entity.Delivery, len(relays))
+
+	var wg sync.WaitGroup
+	for i, relay := range relays {
+		wg.Add(1)
+		go func(i int, relay string) {
+			defer wg.Done()
+			deliveries[i] = publish(ctx, relay, event, timeout)
+		}(i, relay)
+	}
+	wg.Wait()
+	return deliveries
+}
+
+// publish sends an event to a relay and waits for its OK message (NIP-20).
+// The timeout covers the whole exchange including the handshake.
+func publish(ctx context.Context, relay string, event Event, timeout time.Duration) entity.Delivery {
+	delivery := entity.Delivery{Target: relay}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ws, err := dial(ctx, relay)
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			err = fmt.Errorf("No handshake within %s", timeout)
+		}
+		delivery.Message = err.Error()
+		return delivery
+	}
+	defer ws.Close()
+
+	if err := websocket.JSON.Send(ws, []interface{}{"EVENT", event}); err != nil {
+		delivery.Message = err.Error()
+		return delivery
+	}
+
+	// Relays may send notices and other messages before the OK message
+	for {
+		var msg []json.RawMessage
+		if err := websocket.JSON.Receive(ws, &msg); err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				err = fmt.Errorf("No OK message within %s", timeout)
+			}
+			if delivery.Message == "" {
+				delivery.Message = err.Error()
+			}
+			return delivery
+		}
+		if len(msg) < 2 {
+			continue
+		}
+
+		var typ string
+		json.Unmarshal(msg[0], &typ)
+		switch typ {
+		case "NOTICE":
+			json.Unmarshal(msg[1], &delivery.Message)
+		case "OK":
+			var id string
+			json.Unmarshal(msg[1], &id)
+			if id != event.ID || len(msg) < 3 {
+				continue
+			}
+			json.Unmarshal(msg[2], &delivery.Accepted)
+			delivery.Message = ""
+			if len(msg) > 3 {
+				json.Unmarshal(msg[3], &delivery.Message)
+			}
+			return delivery
+		}
+	}
+}
+
+// dial opens a WebSocket connection to a relay. The connection is closed
+// when ctx is done and gets the deadline of ctx.
+func dial(ctx context.Context, relay string) (*websocket.Conn, error) {
+	conf, err := websocket.NewConfig(relay, origin)
+	if err != nil {
+		return nil, err
+	}
+	if conf.Location.Scheme != "ws" && conf.Location.Scheme != "wss" {
+		return nil, fmt.Errorf("Unsupported relay URL scheme: %s", conf.Location.Scheme)
+	}
+	host := conf.Location.Host
+	if conf.Location.Port() == "" {
+		port := "80"
+		if conf.Location.Scheme == "wss" {
+			port = "443"
+		}
+		host = net.JoinHostPort(conf.Location.Hostname(), port)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if conf.Location.Scheme == "wss" {
+		conn = tls.Client(conn, &tls.Config{ServerName: conf.Location.Hostname()})
+	}
+
+	// x/net/websocket doesn't support contexts
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	ws, err := websocket.NewClient(conf, conn)
+	if err != nil {
+		conn.Close()
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
+	return ws, nil
+}
@@ -0,0 +1,253 @@
+package nostr
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+	"golang.org/x/net/websocket"
+)
+
+const testKey = "67dea2ed018072d675f5415ecfaed7d2597555e202d85b3d65ea4e58d2d92ffa"
+
+// fakeRelay is an in-process relay. The path of the relay URL selects how
+// it answers.
+type fakeRelay struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu     sync.Mutex
+	events []Event
+}
+
+func newFakeRelay(t *testing.T) *fakeRelay {
+	r := &fakeRelay{t: t}
+	r.server = httptest.NewServer(websocket.Handler(r.handle))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+// url returns the WebSocket URL of path
+func (r *fakeRelay) url(path string) string {
+	return "ws" + strings.TrimPrefix(r.server.URL, "http") + path
+}
+
+// received returns all valid events the relay got
+func (r *fakeRelay) received() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event(nil), r.events...)
+}
+
+func (r *fakeRelay) handle(ws *websocket.Conn) {
+	defer ws.Close()
+
+	var msg []json.RawMessage
+	if err := websocket.JSON.Receive(ws, &msg); err != nil {
+		r.t.Errorf("Couldn't receive message: %s", err)
+		return
+	}
+	var typ string
+	var event Event
+	if len(msg) != 2 || json.Unmarshal(msg[0], &typ) != nil || typ != "EVENT" || json.Unmarshal(msg[1], &event) != nil {
+		r.t.Errorf("Unexpected message: %s", msg)
+		return
+	}
+	if !verify(r.t, event) {
+		r.t.Errorf("Event has an invalid signature: %+v", event)
+		return
+	}
+	r.mu.Lock()
+	r.events = append(r.events, event)
+	r.mu.Unlock()
+
+	switch ws.Request().URL.Path {
+	case "/ok":
+		websocket.JSON.Send(ws, []interface{}{"NOTICE", "welcome"})
+		websocket.JSON.Send(ws, []interface{}{"OK", strings.Repeat("0", 64), false, "other event"})
+		websocket.JSON.Send(ws, []interface{}{"OK", event.ID, true, ""})
+	case "/reject":
+		websocket.JSON.Send(ws, []interface{}{"OK", event.ID, false, "blocked: not on allowlist"})
+	case "/notice":
+		websocket.JSON.Send(ws, []interface{}{"NOTICE", "rate limited"})
+		time.Sleep(time.Second)
+	case "/silent":
+		time.Sleep(time.Second)
+	case "/close":
+		// Close without answering
+	}
+}
+
+// stallingRelay accepts TCP connections but never answers the WebSocket
+// handshake
+func stallingRelay(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	conns := make([]net.Conn, 0)
+	t.Cleanup(func() {
+		l.Close()
+		mu.Lock()
+		defer mu.Unlock()
+		for _, c := range conns {
+			c.Close()
+		}
+	})
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns = append(conns, conn)
+			mu.Unlock()
+		}
+	}()
+	return "ws://" + l.Addr().String() + "/"
+}
+
+func signedEvent(t *testing.T) Event {
+	t.Helper()
+	e := Event{CreatedAt: time.Now().Unix(), Kind: KindTextNote, Content: "Hello"}
+	if err := e.Sign(testKey); err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestBroadcast(t *testing.T) {
+	relay := newFakeRelay(t)
+	stalling := stallingRelay(t)
+	event := signedEvent(t)
+
+	relays := []string{
+		relay.url("/ok"),
+		relay.url("/reject"),
+		relay.url("/notice"),
+		relay.url("/silent"),
+		relay.url("/close"),
+		stalling,
+		"http://not-a-websocket",
+	}
+	timeout := 200 * time.Millisecond
+	start := time.Now()
+	deliveries := broadcast(context.Background(), relays, event, timeout)
+	if elapsed := time.Since(start); elapsed > 2*timeout+100*time.Millisecond {
+		t.Errorf("broadcast took %s (timeout %s)", elapsed, timeout)
+	}
+
+	want := []struct {
+		accepted bool
+		message  string
+	}{
+		{true, ""},
+		{false, "blocked: not on allowlist"},
+		{false, "rate limited"},
+		{false, "No OK message within 200ms"},
+		{false, "EOF"},
+		{false, "No handshake within 200ms"},
+		{false, "Unsupported relay URL scheme"},
+	}
+	if len(deliveries) != len(want) {
+		t.Fatalf("Got %d deliveries, want %d", len(deliveries), len(want))
+	}
+	for i, d := range deliveries {
+		if d.Target != relays[i] {
+			t.Errorf("Delivery %d is for %s, want %s", i, d.Target, relays[i])
+		}
+		if d.Accepted != want[i].accepted || !strings.Contains(d.Message, want[i].message) {
+			t.Errorf("%s: got accepted=%v message=%q, want accepted=%v message=%q",
+				relays[i], d.Accepted, d.Message, want[i].accepted, want[i].message)
+		}
+	}
+
+	for _, e := range relay.received() {
+		if e.ID != event.ID {
+			t.Errorf("Relay got event %s, want %s", e.ID, event.ID)
+		}
+	}
+}
+
+func TestPublishCancelled(t *testing.T) {
+	stalling := stallingRelay(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	d := publish(ctx, stalling, signedEvent(t), time.Minute)
+	if time.Since(start) > time.Second {
+		t.Errorf("publish didn't stop when the context was cancelled")
+	}
+	if d.Accepted || !strings.Contains(d.Message, "canceled") {
+		t.Errorf("Got %+v", d)
+	}
+}
+
+func TestShareRepository(t *testing.T) {
+	relay := newFakeRelay(t)
+	data, _ := hex.DecodeString(testKey)
+	nsec, _ := Encode("nsec", data)
+	identity := entity.IdentityProvider{Provider: "nostr", AccessToken: nsec}
+	article := entity.ArticleShare{URL: "https://example.com/post", Title: "Post", Comment: "Read this"}
+
+	// One accepting relay is enough
+	repo := NewShareRepository(config.NostrConfig{
+		Relays:  []string{relay.url("/reject"), relay.url("/ok")},
+		Timeout: time.Second,
+	}, identity)
+	result, err := repo.ShareArticle(context.Background(), article)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Deliveries) != 2 || result.Deliveries[0].Accepted || !result.Deliveries[1].Accepted {
+		t.Errorf("Unexpected deliveries: %+v", result.Deliveries)
+	}
+	if !strings.HasPrefix(result.PostURL, "https://njump.me/note1") {
+		t.Errorf("PostURL = %s", result.PostURL)
+	}
+
+	received := relay.received()
+	note := received[len(received)-1]
+	if note.ID != result.PostID || note.Kind != KindTextNote {
+		t.Errorf("Relay got %+v, want note %s", note, result.PostID)
+	}
+	if note.Content != "Post\nhttps://example.com/post\n\nRead this" {
+		t.Errorf("Content = %q", note.Content)
+	}
+	if len(note.Tags) != 1 || note.Tags[0][0] != "r" || note.Tags[0][1] != article.URL {
+		t.Errorf("Tags = %v", note.Tags)
+	}
+
+	// Deletion requests reference the note
+	if err := repo.DeletePost(context.Background(), result.PostID); err != nil {
+		t.Fatal(err)
+	}
+	received = relay.received()
+	deletion := received[len(received)-1]
+	if deletion.Kind != KindDeletion || len(deletion.Tags) != 1 || deletion.Tags[0][1] != result.PostID {
+		t.Errorf("Unexpected deletion: %+v", deletion)
+	}
+
+	// Fail if no relay accepts
+	repo = NewShareRepository(config.NostrConfig{
+		Relays:  []string{relay.url("/reject")},
+		Timeout: time.Second,
+	}, identity)
+	if _, err := repo.ShareArticle(context.Background(), article); err == nil || !strings.Contains(err.Error(), "No relay accepted") {
+		t.Errorf("Got error %v", err)
+	}
+}
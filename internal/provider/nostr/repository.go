@@ -0,0 +1,125 @@
+package nostr
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+// defaultTimeout is used if no relay timeout is configured
+const defaultTimeout = 10 * time.Second
+
+// ShareRepository publishes notes to the configured relays. The identity
+// holds the private key as access token.
+type ShareRepository struct {
+	conf config.NostrConfig
+	key  string
+}
+
+func NewShareRepository(conf config.NostrConfig, identity entity.IdentityProvider) *ShareRepository {
+	return &ShareRepository{
+		conf: conf,
+		key:  identity.AccessToken,
+	}
+}
+
+// composeNote formats an article as text note: the title and URL followed
+// by the comment. The URL is referenced by an "r" tag.
+func (r *ShareRepository) composeNote(article entity.ArticleShare) Event {
+	content := article.URL
+	if article.Title != "" {
+		content = article.Title + "\n" + article.URL
+	}
+	if article.Comment != "" {
+		content += "\n\n" + article.Comment
+	}
+	return Event{
+		CreatedAt: time.Now().Unix(),
+		Kind:      KindTextNote,
+		Tags:      [][]string{{"r", article.URL}},
+		Content:   content,
+	}
+}
+
+// PreviewArticle returns the signed event which would be published
+func (r *ShareRepository) PreviewArticle(ctx context.Context, article entity.ArticleShare) (entity.SharePreview, error) {
+	event := r.composeNote(article)
+	if err := r.sign(&event); err != nil {
+		return entity.SharePreview{}, err
+	}
+	return entity.SharePreview{
+		Text:    event.Content,
+		Payload: event,
+	}, nil
+}
+
+// ShareArticle publishes the article as text note. It fails if no relay
+// accepts the note.
+func (r *ShareRepository) ShareArticle(ctx context.Context, article entity.ArticleShare) (entity.ShareResult, error) {
+	event := r.composeNote(article)
+	deliveries, err := r.publish(ctx, &event)
+	if err != nil {
+		return entity.ShareResult{}, err
+	}
+
+	id, _ := hex.DecodeString(event.ID)
+	note, err := Encode("note", id)
+	if err != nil {
+		return entity.ShareResult{}, err
+	}
+	return entity.ShareResult{
+		PostID:     event.ID,
+		PostURL:    "https://njump.me/" + note,
+		Deliveries: deliveries,
+	}, nil
+}
+
+// DeletePost publishes a deletion request (NIP-09) for a note
+func (r *ShareRepository) DeletePost(ctx context.Context, postID string) error {
+	event := Event{
+		CreatedAt: time.Now().Unix(),
+		Kind:      KindDeletion,
+		Tags:      [][]string{{"e", postID}},
+		Content:   "Deleted via gocial",
+	}
+	_, err := r.publish(ctx, &event)
+	return err
+}
+
+// publish signs the event and sends it to all relays
+func (r *ShareRepository) publish(ctx context.Context, event *Event) ([]entity.Delivery, error) {
+	if len(r.conf.Relays) == 0 {
+		return nil, fmt.Errorf("No Nostr relays configured (nostr.relays)")
+	}
+	if err := r.sign(event); err != nil {
+		return nil, err
+	}
+
+	timeout := r.conf.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	deliveries := broadcast(ctx, r.conf.Relays, *event, timeout)
+
+	rejected := make([]string, 0)
+	for _, d := range deliveries {
+		if d.Accepted {
+			return deliveries, nil
+		}
+		rejected = append(rejected, fmt.Sprintf("%s: %s", d.Target, d.Message))
+	}
+	return deliveries, fmt.Errorf("No relay accepted the event: %s", strings.Join(rejected, "; "))
+}
+
+func (r *ShareRepository) sign(event *Event) error {
+	key, err := decodeKey(r.key)
+	if err != nil {
+		return err
+	}
+	return event.Sign(key)
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+// +build !windows
+
+package plugin
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// isolate starts the plugin in its own process group, so that killing it
+// also kills any children it started
+func isolate(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// kill kills the plugin and its children
+func kill(cmd *exec.Cmd) {
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
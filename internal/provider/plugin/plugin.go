@@ -0,0 +1,165 @@
+// Package plugin provides share repositories backed by external executables
+// speaking a JSON protocol over stdin/stdout (see docs/plugins.org). Every
+// request runs in a new process, so a crashing or hanging plugin can't
+// affect gocial.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+const (
+	// defaultTimeout is used if no timeout is configured
+	defaultTimeout = 30 * time.Second
+
+	// maxOutput limits how much of the plugin's output is read
+	maxOutput = 1 << 20
+)
+
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Plugin is an external provider executable
+type Plugin struct {
+	Path     string
+	Info     entity.ProviderInfo
+	Settings map[string]string
+	Timeout  time.Duration
+}
+
+// Discover describes all executables in dir. Plugins which fail to describe
+// themselves are logged and skipped. settings are passed to the plugin of
+// the same name.
+func Discover(ctx context.Context, dir string, timeout time.Duration, settings map[string]map[string]string) ([]*Plugin, error) {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("Couldn't read plugin directory: %s", err)
+	}
+
+	plugins := make([]*Plugin, 0)
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") || f.Mode()&0111 == 0 {
+			continue
+		}
+
+		p := &Plugin{
+			Path:    filepath.Join(dir, f.Name()),
+			Timeout: timeout,
+		}
+		if err := p.describe(ctx); err != nil {
+			log.Printf("Skipping plugin %s: %s\n", f.Name(), err)
+			continue
+		}
+		p.Settings = settings[p.Info.Name]
+		plugins = append(plugins, p)
+	}
+	return plugins, nil
+}
+
+// describe asks the plugin for its name and capabilities. The name
+// defaults to the file name without extension.
+func (p *Plugin) describe(ctx context.Context) error {
+	resp, err := p.call(ctx, Request{Method: MethodDescribe})
+	if err != nil {
+		return err
+	}
+	if resp.Info == nil {
+		return fmt.Errorf("Plugin didn't describe itself")
+	}
+
+	p.Info = *resp.Info
+	if p.Info.Name == "" {
+		base := filepath.Base(p.Path)
+		p.Info.Name = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	if !validName.MatchString(p.Info.Name) {
+		return fmt.Errorf("Invalid plugin name: %q", p.Info.Name)
+	}
+	if p.Info.DisplayName == "" {
+		p.Info.DisplayName = p.Info.Name
+	}
+	return nil
+}
+
+// call runs the plugin for a single request
+func (p *Plugin) call(ctx context.Context, req Request) (Response, error) {
+	req.Protocol = ProtocolVersion
+	if req.Settings == nil {
+		req.Settings = p.Settings
+	}
+	input, err := json.Marshal(req)
+	if err != nil {
+		return Response{}, err
+	}
+
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr limitedBuffer
+	cmd := exec.Command(p.Path)
+	cmd.Stdin = bytes.NewReader(append(input, '\n'))
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	isolate(cmd)
+	if err := cmd.Start(); err != nil {
+		return Response{}, fmt.Errorf("Couldn't start plugin %s: %s", filepath.Base(p.Path), err)
+	}
+
+	// Kill the plugin (and its children) once the timeout is reached
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			kill(cmd)
+		case <-done:
+		}
+	}()
+	runErr := cmd.Wait()
+	close(done)
+
+	if ctx.Err() != nil {
+		return Response{}, fmt.Errorf("Plugin %s timed out after %s", filepath.Base(p.Path), timeout)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		if runErr != nil {
+			return Response{}, fmt.Errorf("Plugin %s failed: %s: %s", filepath.Base(p.Path), runErr, strings.TrimSpace(stderr.String()))
+		}
+		return Response{}, fmt.Errorf("Couldn't parse response of plugin %s: %s", filepath.Base(p.Path), err)
+	}
+	return resp, nil
+}
+
+// limitedBuffer discards everything written beyond maxOutput
+type limitedBuffer struct {
+	bytes.Buffer
+}
+
+func (b *limitedBuffer) Write(data []byte) (int, error) {
+	if room := maxOutput - b.Len(); room < len(data) {
+		if room > 0 {
+			b.Buffer.Write(data[:room])
+		}
+		return len(data), nil
+	}
+	return b.Buffer.Write(data)
+}
@@ -0,0 +1,50 @@
+package plugin
+
+import "github.com/dorneanu/gocial/internal/entity"
+
+// ProtocolVersion is the version of the plugin protocol described in
+// docs/plugins.org
+const ProtocolVersion = 1
+
+// Methods of the plugin protocol
+const (
+	MethodDescribe = "describe"
+	MethodShare    = "share"
+	MethodPreview  = "preview"
+	MethodEdit     = "edit"
+	MethodDelete   = "delete"
+	MethodMetrics  = "metrics"
+)
+
+// ErrorCodeNotSupported is returned by plugins for methods they don't
+// implement
+const ErrorCodeNotSupported = "not_supported"
+
+// Request is written as a single JSON line to the plugin's stdin
+type Request struct {
+	Protocol int                  `json:"protocol"`
+	Method   string               `json:"method"`
+	Settings map[string]string    `json:"settings,omitempty"`
+	Identity *Identity            `json:"identity,omitempty"`
+	Article  *entity.ArticleShare `json:"article,omitempty"`
+	PostID   string               `json:"post_id,omitempty"`
+}
+
+// Identity is the identity of the user (if any is stored for the plugin)
+type Identity struct {
+	UserName          string `json:"user_name,omitempty"`
+	UserID            string `json:"user_id,omitempty"`
+	AccessToken       string `json:"access_token,omitempty"`
+	AccessTokenSecret string `json:"access_token_secret,omitempty"`
+	RefreshToken      string `json:"refresh_token,omitempty"`
+}
+
+// Response is read as JSON from the plugin's stdout
+type Response struct {
+	Error     string               `json:"error,omitempty"`
+	ErrorCode string               `json:"error_code,omitempty"`
+	Info      *entity.ProviderInfo `json:"info,omitempty"`
+	Result    *entity.ShareResult  `json:"result,omitempty"`
+	Preview   *entity.SharePreview `json:"preview,omitempty"`
+	Metrics   *entity.PostMetrics  `json:"metrics,omitempty"`
+}
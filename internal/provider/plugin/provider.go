@@ -0,0 +1,20 @@
+package plugin
+
+import (
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/provider"
+	"github.com/dorneanu/gocial/internal/share"
+)
+
+// Provider returns the registry entry of a plugin. Plugins manage their own
+// credentials, so no identity is required.
+func (p *Plugin) Provider() provider.Provider {
+	return provider.Provider{
+		ProviderInfo: p.Info,
+		NoIdentity:   true,
+		NewRepository: func(client config.ProviderConfig, id entity.IdentityProvider) (share.Repository, error) {
+			return NewRepository(p, id), nil
+		},
+	}
+}
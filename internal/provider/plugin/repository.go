@@ -0,0 +1,102 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/share"
+)
+
+// Repository implements share.Repository (and all optional capabilities)
+// by calling a plugin. Operations the plugin doesn't support return
+// share.ErrNotSupported.
+type Repository struct {
+	plugin   *Plugin
+	identity *Identity
+}
+
+func NewRepository(p *Plugin, id entity.IdentityProvider) *Repository {
+	return &Repository{
+		plugin: p,
+		identity: &Identity{
+			UserName:          id.UserName,
+			UserID:            id.UserID,
+			AccessToken:       id.AccessToken,
+			AccessTokenSecret: id.AccessTokenSecret,
+			RefreshToken:      id.RefreshToken,
+		},
+	}
+}
+
+// do sends a request and turns error responses into errors
+func (r *Repository) do(ctx context.Context, req Request) (Response, error) {
+	req.Identity = r.identity
+	resp, err := r.plugin.call(ctx, req)
+	if err != nil {
+		return Response{}, err
+	}
+	if resp.ErrorCode == ErrorCodeNotSupported {
+		return Response{}, share.ErrNotSupported
+	}
+	if resp.Error != "" {
+		return Response{}, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+func (r *Repository) ShareArticle(ctx context.Context, article entity.ArticleShare) (entity.ShareResult, error) {
+	resp, err := r.do(ctx, Request{Method: MethodShare, Article: &article})
+	if err != nil {
+		return entity.ShareResult{}, err
+	}
+	if resp.Result == nil {
+		return entity.ShareResult{}, nil
+	}
+	return *resp.Result, nil
+}
+
+func (r *Repository) PreviewArticle(ctx context.Context, article entity.ArticleShare) (entity.SharePreview, error) {
+	resp, err := r.do(ctx, Request{Method: MethodPreview, Article: &article})
+	if err != nil {
+		return entity.SharePreview{}, err
+	}
+	if resp.Preview == nil {
+		return entity.SharePreview{}, share.ErrNotSupported
+	}
+	preview := *resp.Preview
+	if preview.MaxLength == 0 {
+		preview.MaxLength = r.plugin.Info.Capabilities.MaxLength
+	}
+	return preview, nil
+}
+
+func (r *Repository) EditPost(ctx context.Context, postID string, article entity.ArticleShare) error {
+	if !r.plugin.Info.Capabilities.Edit {
+		return share.ErrNotSupported
+	}
+	_, err := r.do(ctx, Request{Method: MethodEdit, PostID: postID, Article: &article})
+	return err
+}
+
+func (r *Repository) DeletePost(ctx context.Context, postID string) error {
+	if !r.plugin.Info.Capabilities.Delete {
+		return share.ErrNotSupported
+	}
+	_, err := r.do(ctx, Request{Method: MethodDelete, PostID: postID})
+	return err
+}
+
+func (r *Repository) GetMetrics(ctx context.Context, postID string) (entity.PostMetrics, error) {
+	if !r.plugin.Info.Capabilities.Metrics {
+		return entity.PostMetrics{}, share.ErrNotSupported
+	}
+	resp, err := r.do(ctx, Request{Method: MethodMetrics, PostID: postID})
+	if err != nil {
+		return entity.PostMetrics{}, err
+	}
+	if resp.Metrics == nil {
+		return entity.PostMetrics{}, nil
+	}
+	return *resp.Metrics, nil
+}
@@ -0,0 +1,144 @@
+package reddit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	apiURL = "https://oauth.reddit.com"
+
+	// maxRateLimitWait is the longest time gocial waits for the rate limit
+	// to be reset before giving up
+	maxRateLimitWait = time.Minute
+)
+
+// userAgentTransport sets the User-Agent Reddit requires for API clients
+// (https://github.com/reddit-archive/reddit/wiki/API)
+type userAgentTransport struct {
+	userAgent string
+	base      http.RoundTripper
+}
+
+func (t userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent)
+	return t.base.RoundTrip(req)
+}
+
+// httpClient returns an HTTP client sending the User-Agent of user
+func httpClient(user string) *http.Client {
+	userAgent := "web:gocial:v1 (+https://github.com/dorneanu/gocial)"
+	if user != "" {
+		userAgent = fmt.Sprintf("web:gocial:v1 (by /u/%s)", user)
+	}
+	return &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: userAgentTransport{userAgent: userAgent, base: http.DefaultTransport},
+	}
+}
+
+// client calls the Reddit API. Expired access tokens are refreshed
+// automatically.
+type client struct {
+	http *http.Client
+
+	// Rate limit of the last response
+	remaining float64
+	reset     time.Time
+}
+
+func newClient(ctx context.Context, conf *oauth2.Config, token *oauth2.Token, user string) *client {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient(user))
+	return &client{
+		http:      oauth2.NewClient(ctx, conf.TokenSource(ctx, token)),
+		remaining: -1,
+	}
+}
+
+// waitForRateLimit blocks until the rate limit is reset if no requests are
+// left
+func (c *client) waitForRateLimit(ctx context.Context) error {
+	if c.remaining < 0 || c.remaining >= 1 {
+		return nil
+	}
+	wait := time.Until(c.reset)
+	if wait <= 0 {
+		return nil
+	}
+	if wait > maxRateLimitWait {
+		return fmt.Errorf("Reddit rate limit exceeded (reset in %s)", wait.Round(time.Second))
+	}
+
+	select {
+	case <-time.After(wait):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// updateRateLimit remembers the rate limit sent with a response
+func (c *client) updateRateLimit(resp *http.Response) {
+	remaining, err := strconv.ParseFloat(resp.Header.Get("X-Ratelimit-Remaining"), 64)
+	if err != nil {
+		return
+	}
+	reset, err := strconv.Atoi(resp.Header.Get("X-Ratelimit-Reset"))
+	if err != nil {
+		return
+	}
+	c.remaining = remaining
+	c.reset = time.Now().Add(time.Duration(reset) * time.Second)
+}
+
+// do sends a request to the API. form is sent as POST body.
+func (c *client) do(ctx context.Context, method, path string, form url.Values, result interface{}) error {
+	if err := c.waitForRateLimit(ctx); err != nil {
+		return err
+	}
+
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+
+	// Create new HTTP request
+	req, err := http.NewRequestWithContext(ctx, method, apiURL+path, body)
+	if err != nil {
+		return fmt.Errorf("Couldn't create request: %s", err)
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	// Send request
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("Couldn't call Reddit: %s", err)
+	}
+	defer resp.Body.Close()
+	c.updateRateLimit(resp)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("Reddit rate limit exceeded (reset in %s)", time.Until(c.reset).Round(time.Second))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("Reddit returned %s for %s", resp.Status, path)
+	}
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("Couldn't unmarshalize response: %s", err)
+		}
+	}
+	return nil
+}
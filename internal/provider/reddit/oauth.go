@@ -0,0 +1,147 @@
+package reddit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+const (
+	authURL  = "https://www.reddit.com/api/v1/authorize"
+	tokenURL = "https://www.reddit.com/api/v1/access_token"
+)
+
+// endpoint is the OAuth endpoint of Reddit. Client credentials are sent
+// using basic auth.
+var endpoint = oauth2.Endpoint{
+	AuthURL:   authURL,
+	TokenURL:  tokenURL,
+	AuthStyle: oauth2.AuthStyleInHeader,
+}
+
+// OAuthProvider implements goth.Provider for Reddit. goth doesn't ship one.
+type OAuthProvider struct {
+	config *oauth2.Config
+	name   string
+}
+
+func NewOAuthProvider(clientID, clientSecret, callbackURL string, scopes ...string) *OAuthProvider {
+	return &OAuthProvider{
+		config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  callbackURL,
+			Endpoint:     endpoint,
+			Scopes:       scopes,
+		},
+		name: "reddit",
+	}
+}
+
+func (p *OAuthProvider) Name() string {
+	return p.name
+}
+
+func (p *OAuthProvider) SetName(name string) {
+	p.name = name
+}
+
+func (p *OAuthProvider) Debug(bool) {}
+
+// BeginAuth returns the authorization URL. Reddit only issues refresh
+// tokens for permanent grants, access tokens expire after an hour.
+func (p *OAuthProvider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state, oauth2.SetAuthURLParam("duration", "permanent")),
+	}, nil
+}
+
+func (p *OAuthProvider) UnmarshalSession(data string) (goth.Session, error) {
+	s := &Session{}
+	err := json.NewDecoder(strings.NewReader(data)).Decode(s)
+	return s, err
+}
+
+// FetchUser returns the logged in Reddit user
+func (p *OAuthProvider) FetchUser(session goth.Session) (goth.User, error) {
+	s := session.(*Session)
+	user := goth.User{
+		Provider:     p.name,
+		AccessToken:  s.AccessToken,
+		RefreshToken: s.RefreshToken,
+		ExpiresAt:    s.ExpiresAt,
+	}
+	if user.AccessToken == "" {
+		return user, fmt.Errorf("%s cannot get user information without access token", p.name)
+	}
+
+	c := newClient(context.Background(), p.config, &oauth2.Token{AccessToken: s.AccessToken}, "")
+	var me struct {
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		IconImg string `json:"icon_img"`
+	}
+	if err := c.do(context.Background(), http.MethodGet, "/api/v1/me", nil, &me); err != nil {
+		return user, err
+	}
+	user.UserID = me.ID
+	user.Name = me.Name
+	user.NickName = me.Name
+	// Icon URLs are HTML escaped
+	user.AvatarURL = strings.ReplaceAll(me.IconImg, "&amp;", "&")
+	return user, nil
+}
+
+func (p *OAuthProvider) RefreshTokenAvailable() bool {
+	return true
+}
+
+func (p *OAuthProvider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient(""))
+	return p.config.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+}
+
+// Session stores data during the auth process with Reddit
+type Session struct {
+	AuthURL      string
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+func (s *Session) GetAuthURL() (string, error) {
+	if s.AuthURL == "" {
+		return "", errors.New(goth.NoAuthUrlErrorMessage)
+	}
+	return s.AuthURL, nil
+}
+
+// Authorize exchanges the authorization code for tokens
+func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	p := provider.(*OAuthProvider)
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient(""))
+	token, err := p.config.Exchange(ctx, params.Get("code"))
+	if err != nil {
+		return "", err
+	}
+	if !token.Valid() {
+		return "", errors.New("Invalid token received from provider")
+	}
+
+	s.AccessToken = token.AccessToken
+	s.RefreshToken = token.RefreshToken
+	s.ExpiresAt = token.Expiry
+	return token.AccessToken, nil
+}
+
+func (s *Session) Marshal() string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
@@ -0,0 +1,34 @@
+// Package reddit submits shared articles as links to subreddits
+package reddit
+
+import (
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/provider"
+	"github.com/dorneanu/gocial/internal/share"
+	"github.com/markbates/goth"
+)
+
+// Provider returns the registry entry of Reddit
+func Provider(conf config.RedditConfig) provider.Provider {
+	return provider.Provider{
+		ProviderInfo: entity.ProviderInfo{
+			Name:        "reddit",
+			DisplayName: "Reddit",
+			Capabilities: entity.ProviderCapabilities{
+				MaxLength:  redditMaxCharacters,
+				Delete:     true,
+				Metrics:    true,
+				Subreddits: true,
+			},
+		},
+		// edit is needed to delete submissions
+		Scopes: []string{"identity", "submit", "flair", "edit", "read"},
+		OAuth: func(clientID, clientSecret, callbackURL string, scopes []string) goth.Provider {
+			return NewOAuthProvider(clientID, clientSecret, callbackURL, scopes...)
+		},
+		NewRepository: func(client config.ProviderConfig, identity entity.IdentityProvider) (share.Repository, error) {
+			return NewShareRepository(conf, client, identity), nil
+		},
+	}
+}
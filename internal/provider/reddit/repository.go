@@ -0,0 +1,274 @@
+package reddit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/share"
+	"golang.org/x/oauth2"
+)
+
+// redditMaxCharacters is the maximum length of a submission title
+const redditMaxCharacters = 300
+
+// target is a subreddit and the flair (ID or text) to select
+type target struct {
+	Subreddit string `json:"subreddit"`
+	Flair     string `json:"flair,omitempty"`
+}
+
+// Submission is the form sent to /api/submit for a single subreddit
+type Submission struct {
+	Subreddit   string `json:"sr"`
+	Kind        string `json:"kind"`
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Flair       string `json:"flair,omitempty"`
+	SendReplies bool   `json:"sendreplies"`
+}
+
+// submitResponse is the response of /api/submit with api_type=json
+type submitResponse struct {
+	JSON struct {
+		// Errors are [code, message, field] triples
+		Errors [][]string `json:"errors"`
+		Data   struct {
+			URL  string `json:"url"`
+			Name string `json:"name"`
+		} `json:"data"`
+	} `json:"json"`
+}
+
+// ShareRepository submits links to subreddits
+type ShareRepository struct {
+	conf   config.RedditConfig
+	client *client
+}
+
+func NewShareRepository(conf config.RedditConfig, client config.ProviderConfig, identity entity.IdentityProvider) *ShareRepository {
+	oauthConf := &oauth2.Config{
+		ClientID:     client.ClientID,
+		ClientSecret: client.ClientSecret,
+		Endpoint:     endpoint,
+	}
+	token := &oauth2.Token{
+		AccessToken:  identity.AccessToken,
+		RefreshToken: identity.RefreshToken,
+	}
+	if identity.ExpiresAt != nil {
+		token.Expiry = *identity.ExpiresAt
+	}
+	return &ShareRepository{
+		conf:   conf,
+		client: newClient(context.Background(), oauthConf, token, identity.UserName),
+	}
+}
+
+// targets returns the subreddits of an article (or the configured ones)
+func (r *ShareRepository) targets(article entity.ArticleShare) ([]target, error) {
+	names := strings.Split(article.Subreddits, ",")
+	if strings.TrimSpace(article.Subreddits) == "" {
+		names = r.conf.Subreddits
+	}
+
+	targets := make([]target, 0)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		parts := strings.SplitN(name, ":", 2)
+		t := target{Subreddit: strings.TrimPrefix(strings.TrimPrefix(parts[0], "/"), "r/")}
+		if len(parts) == 2 {
+			t.Flair = strings.TrimSpace(parts[1])
+		}
+		targets = append(targets, t)
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("No subreddit given")
+	}
+	return targets, nil
+}
+
+// submissions builds the submissions of an article
+func (r *ShareRepository) submissions(article entity.ArticleShare) ([]Submission, error) {
+	if n := len([]rune(article.Title)); n > redditMaxCharacters {
+		return nil, fmt.Errorf("Post max characters exceeded: %d (allowed: %d)", n, redditMaxCharacters)
+	}
+	targets, err := r.targets(article)
+	if err != nil {
+		return nil, err
+	}
+
+	submissions := make([]Submission, 0, len(targets))
+	for _, t := range targets {
+		submissions = append(submissions, Submission{
+			Subreddit:   t.Subreddit,
+			Kind:        "link",
+			Title:       article.Title,
+			URL:         article.URL,
+			Flair:       t.Flair,
+			SendReplies: r.conf.SendReplies,
+		})
+	}
+	return submissions, nil
+}
+
+// flairID returns the ID of the link flair matching flair (an ID or text)
+func (r *ShareRepository) flairID(ctx context.Context, subreddit, flair string) (string, error) {
+	var flairs []struct {
+		ID   string `json:"id"`
+		Text string `json:"text"`
+	}
+	path := fmt.Sprintf("/r/%s/api/link_flair_v2", url.PathEscape(subreddit))
+	if err := r.client.do(ctx, http.MethodGet, path, nil, &flairs); err != nil {
+		return "", fmt.Errorf("Couldn't get flairs of r/%s: %s", subreddit, err)
+	}
+	for _, f := range flairs {
+		if f.ID == flair || strings.EqualFold(f.Text, flair) {
+			return f.ID, nil
+		}
+	}
+	return "", fmt.Errorf("Unknown flair %q in r/%s", flair, subreddit)
+}
+
+// PreviewArticle returns the submissions which would be sent
+func (r *ShareRepository) PreviewArticle(ctx context.Context, article entity.ArticleShare) (entity.SharePreview, error) {
+	submissions, err := r.submissions(article)
+	if err != nil {
+		return entity.SharePreview{}, err
+	}
+	return entity.SharePreview{
+		Text:      article.Title,
+		MaxLength: redditMaxCharacters,
+		Payload:   submissions,
+	}, nil
+}
+
+// ShareArticle submits the article as link to all subreddits. Subreddits
+// which already contain the link are skipped; share.ErrDuplicate is
+// returned if the link was already submitted to all of them. The post ID
+// holds the fullnames of all submissions, the post URL is the first
+// submission. Failing subreddits don't stop the others; the result lists
+// the outcome per subreddit and an error is only returned if nothing was
+// submitted.
+func (r *ShareRepository) ShareArticle(ctx context.Context, article entity.ArticleShare) (entity.ShareResult, error) {
+	submissions, err := r.submissions(article)
+	if err != nil {
+		return entity.ShareResult{}, err
+	}
+
+	// Resolve flairs before anything gets submitted
+	flairIDs := make([]string, len(submissions))
+	for i, s := range submissions {
+		if s.Flair == "" {
+			continue
+		}
+		if flairIDs[i], err = r.flairID(ctx, s.Subreddit, s.Flair); err != nil {
+			return entity.ShareResult{}, err
+		}
+	}
+
+	// Every subreddit is tried. Submissions which succeeded are returned
+	// even if others failed, so they can be deleted and measured later.
+	names := make([]string, 0)
+	urls := make([]string, 0)
+	duplicates := make([]string, 0)
+	failures := make([]string, 0)
+	deliveries := make([]entity.Delivery, 0, len(submissions))
+	for i, s := range submissions {
+		target := "r/" + s.Subreddit
+		form := url.Values{
+			"api_type":    {"json"},
+			"kind":        {s.Kind},
+			"sr":          {s.Subreddit},
+			"title":       {s.Title},
+			"url":         {s.URL},
+			"resubmit":    {"false"},
+			"sendreplies": {strconv.FormatBool(s.SendReplies)},
+		}
+		if flairIDs[i] != "" {
+			form.Set("flair_id", flairIDs[i])
+		}
+
+		var resp submitResponse
+		err := r.client.do(ctx, http.MethodPost, "/api/submit", form, &resp)
+		if err == nil && len(resp.JSON.Errors) > 0 && len(resp.JSON.Errors[0]) > 0 {
+			e := resp.JSON.Errors[0]
+			if e[0] == "ALREADY_SUB" {
+				duplicates = append(duplicates, target)
+				deliveries = append(deliveries, entity.Delivery{Target: target, Message: "Already submitted"})
+				continue
+			}
+			// e.g. RATELIMIT: you are doing that too much
+			msg := e[0]
+			if len(e) > 1 {
+				msg += ": " + e[1]
+			}
+			err = fmt.Errorf("%s", msg)
+		}
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", target, err))
+			deliveries = append(deliveries, entity.Delivery{Target: target, Message: err.Error()})
+			continue
+		}
+		names = append(names, resp.JSON.Data.Name)
+		urls = append(urls, resp.JSON.Data.URL)
+		deliveries = append(deliveries, entity.Delivery{Target: target, Accepted: true, Message: resp.JSON.Data.URL})
+	}
+
+	if len(names) == 0 {
+		if len(failures) > 0 {
+			return entity.ShareResult{}, fmt.Errorf("Couldn't submit to %s", strings.Join(failures, "; "))
+		}
+		return entity.ShareResult{}, fmt.Errorf("%w to %s", share.ErrDuplicate, strings.Join(duplicates, ", "))
+	}
+	return entity.ShareResult{
+		PostID:     strings.Join(names, ","),
+		PostURL:    urls[0],
+		Deliveries: deliveries,
+	}, nil
+}
+
+// DeletePost deletes all submissions of a share
+func (r *ShareRepository) DeletePost(ctx context.Context, postID string) error {
+	for _, name := range strings.Split(postID, ",") {
+		if err := r.client.do(ctx, http.MethodPost, "/api/del", url.Values{"id": {name}}, nil); err != nil {
+			return fmt.Errorf("Couldn't delete %s: %s", name, err)
+		}
+	}
+	return nil
+}
+
+// GetMetrics sums up score, comments and crossposts of all submissions
+func (r *ShareRepository) GetMetrics(ctx context.Context, postID string) (entity.PostMetrics, error) {
+	var listing struct {
+		Data struct {
+			Children []struct {
+				Data struct {
+					Score         int `json:"score"`
+					NumComments   int `json:"num_comments"`
+					NumCrossposts int `json:"num_crossposts"`
+				} `json:"data"`
+			} `json:"children"`
+		} `json:"data"`
+	}
+	path := "/api/info?id=" + url.QueryEscape(postID)
+	if err := r.client.do(ctx, http.MethodGet, path, nil, &listing); err != nil {
+		return entity.PostMetrics{}, err
+	}
+
+	metrics := entity.PostMetrics{}
+	for _, c := range listing.Data.Children {
+		metrics.Likes += c.Data.Score
+		metrics.Comments += c.Data.NumComments
+		metrics.Reposts += c.Data.NumCrossposts
+	}
+	return metrics, nil
+}
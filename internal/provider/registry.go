@@ -0,0 +1,144 @@
+// Package provider is the registry of all providers gocial can share to.
+// Provider packages register themselves in their init function.
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/crosspost"
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/share"
+	"github.com/markbates/goth"
+)
+
+// OAuthFunc returns the goth provider used for logins
+type OAuthFunc func(clientID, clientSecret, callbackURL string, scopes []string) goth.Provider
+
+// ConnectFunc checks credentials entered by the user (e.g. a bot token) and
+// returns the identity to store, completed by details like the user name
+type ConnectFunc func(ctx context.Context, identity entity.IdentityProvider) (entity.IdentityProvider, error)
+
+// RepositoryFunc returns the share repository for an identity. client holds
+// the configured OAuth client of the provider.
+type RepositoryFunc func(client config.ProviderConfig, identity entity.IdentityProvider) (share.Repository, error)
+
+// CrossPostFunc returns the repository syndicating full articles for an
+// identity
+type CrossPostFunc func(identity entity.IdentityProvider) (crosspost.Repository, error)
+
+// Provider describes a provider and how to use it
+type Provider struct {
+	entity.ProviderInfo
+	// Scopes are requested unless the configuration lists scopes
+	Scopes []string
+	// OAuth is nil for providers whose credentials are kept in the
+	// identity store
+	OAuth OAuthFunc
+	// Connect checks the credentials of providers without OAuth before
+	// they are stored
+	Connect ConnectFunc
+	// OAuth1 providers don't send back the state parameter
+	OAuth1 bool
+	// NoIdentity providers manage their own credentials. They can be used
+	// without an identity being stored.
+	NoIdentity    bool
+	NewRepository RepositoryFunc
+	// NewCrossPostRepository is set by providers which take full articles
+	// (e.g. dev.to). They can't share links unless NewRepository is set.
+	NewCrossPostRepository CrossPostFunc
+}
+
+var (
+	mu        sync.RWMutex
+	providers = make(map[string]Provider)
+)
+
+// Register adds a provider to the registry. It panics if a provider with
+// the same name was already registered.
+func Register(p Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := providers[p.Name]; ok {
+		panic(fmt.Sprintf("provider %s registered twice", p.Name))
+	}
+	providers[p.Name] = p
+}
+
+// Get returns the named provider
+func Get(name string) (Provider, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	p, ok := providers[name]
+	return p, ok
+}
+
+// All returns all registered providers sorted by name
+func All() []Provider {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	all := make([]Provider, 0, len(providers))
+	for _, p := range providers {
+		all = append(all, p)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name < all[j].Name
+	})
+	return all
+}
+
+// Names returns the names of all registered providers
+func Names() []string {
+	names := make([]string, 0)
+	for _, p := range All() {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// Infos returns the display metadata of all registered providers
+func Infos() []entity.ProviderInfo {
+	infos := make([]entity.ProviderInfo, 0)
+	for _, p := range All() {
+		infos = append(infos, p.ProviderInfo)
+	}
+	return infos
+}
+
+// NewRepositoryFactory returns a share.RepositoryFactory creating the
+// repositories of registered providers. clients are the configured OAuth
+// clients.
+func NewRepositoryFactory(clients []config.ProviderConfig) share.RepositoryFactory {
+	return func(identity entity.IdentityProvider) (share.Repository, error) {
+		p, ok := Get(identity.Provider)
+		if !ok || p.NewRepository == nil {
+			return nil, fmt.Errorf("Didn't find repository for provider: %s", identity.Provider)
+		}
+
+		client := config.ProviderConfig{Name: identity.Provider}
+		for _, c := range clients {
+			if c.Name == identity.Provider {
+				client = c
+			}
+		}
+		return p.NewRepository(client, identity)
+	}
+}
+
+// NewCrossPostFactory returns a crosspost.RepositoryFactory creating the
+// cross-post repositories of registered providers
+func NewCrossPostFactory() crosspost.RepositoryFactory {
+	return func(identity entity.IdentityProvider) (crosspost.Repository, error) {
+		p, ok := Get(identity.Provider)
+		if !ok || p.NewCrossPostRepository == nil {
+			return nil, fmt.Errorf("%s doesn't support cross-posting", identity.Provider)
+		}
+		return p.NewCrossPostRepository(identity)
+	}
+}
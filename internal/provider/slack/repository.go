@@ -0,0 +1,139 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+// slackMaxCharacters is the maximum length of the text of a section block
+const slackMaxCharacters = 3000
+
+// Message is a Slack message using Block Kit
+// (https://api.slack.com/reference/block-kit)
+type Message struct {
+	// Text is shown in notifications
+	Text      string  `json:"text"`
+	Blocks    []Block `json:"blocks"`
+	Username  string  `json:"username,omitempty"`
+	IconEmoji string  `json:"icon_emoji,omitempty"`
+	Channel   string  `json:"channel,omitempty"`
+}
+
+type Block struct {
+	Type     string  `json:"type"`
+	Text     *Text   `json:"text,omitempty"`
+	Elements []*Text `json:"elements,omitempty"`
+}
+
+type Text struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// ShareRepository posts messages to a Slack incoming webhook
+type ShareRepository struct {
+	conf   config.SlackConfig
+	client *http.Client
+}
+
+func NewShareRepository(conf config.SlackConfig) *ShareRepository {
+	return &ShareRepository{
+		conf:   conf,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// escape escapes the control characters of Slack's mrkdwn format
+func escape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// composeMessage builds the message of an article: the linked title, the
+// comment and the URL
+func (s *ShareRepository) composeMessage(article entity.ArticleShare) (Message, error) {
+	if n := len([]rune(article.Comment)); n > slackMaxCharacters {
+		return Message{}, fmt.Errorf("Post max characters exceeded: %d (allowed: %d)", n, slackMaxCharacters)
+	}
+
+	blocks := []Block{
+		{
+			Type: "section",
+			Text: &Text{Type: "mrkdwn", Text: fmt.Sprintf("*<%s|%s>*", article.URL, escape(article.Title))},
+		},
+	}
+	if article.Comment != "" {
+		blocks = append(blocks, Block{
+			Type: "section",
+			Text: &Text{Type: "mrkdwn", Text: escape(article.Comment)},
+		})
+	}
+	blocks = append(blocks, Block{
+		Type:     "context",
+		Elements: []*Text{{Type: "mrkdwn", Text: article.URL}},
+	})
+
+	return Message{
+		Text:      escape(fmt.Sprintf("%s %s", article.Title, article.URL)),
+		Blocks:    blocks,
+		Username:  s.conf.Username,
+		IconEmoji: s.conf.IconEmoji,
+		Channel:   s.conf.Channel,
+	}, nil
+}
+
+// PreviewArticle returns the message which would be sent
+func (s *ShareRepository) PreviewArticle(ctx context.Context, article entity.ArticleShare) (entity.SharePreview, error) {
+	msg, err := s.composeMessage(article)
+	if err != nil {
+		return entity.SharePreview{}, err
+	}
+	return entity.SharePreview{
+		Text:      article.Comment,
+		MaxLength: slackMaxCharacters,
+		Payload:   msg,
+	}, nil
+}
+
+// ShareArticle posts the article to the webhook. Incoming webhooks don't
+// return any ID of the message.
+func (s *ShareRepository) ShareArticle(ctx context.Context, article entity.ArticleShare) (entity.ShareResult, error) {
+	msg, err := s.composeMessage(article)
+	if err != nil {
+		return entity.ShareResult{}, err
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return entity.ShareResult{}, fmt.Errorf("Couldn't marshal message: %s", err)
+	}
+
+	// Create new HTTP request
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.conf.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return entity.ShareResult{}, fmt.Errorf("Couldn't create request: %s", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send request
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return entity.ShareResult{}, fmt.Errorf("Couldn't post to Slack: %s", err)
+	}
+	defer resp.Body.Close()
+
+	// Slack answers with a short error code (e.g. "invalid_payload")
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return entity.ShareResult{}, fmt.Errorf("Couldn't post to Slack: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return entity.ShareResult{}, nil
+}
@@ -0,0 +1,31 @@
+// Package slack shares articles to Slack channels via incoming webhooks
+package slack
+
+import (
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/provider"
+	"github.com/dorneanu/gocial/internal/share"
+)
+
+// Provider returns the registry entry of a Slack webhook. The webhook URL
+// is the credential, so no identity is required.
+func Provider(conf config.SlackConfig) provider.Provider {
+	displayName := conf.DisplayName
+	if displayName == "" {
+		displayName = conf.Name
+	}
+	return provider.Provider{
+		ProviderInfo: entity.ProviderInfo{
+			Name:        conf.Name,
+			DisplayName: displayName,
+			Capabilities: entity.ProviderCapabilities{
+				MaxLength: slackMaxCharacters,
+			},
+		},
+		NoIdentity: true,
+		NewRepository: func(client config.ProviderConfig, id entity.IdentityProvider) (share.Repository, error) {
+			return NewShareRepository(conf), nil
+		},
+	}
+}
@@ -0,0 +1,110 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// defaultEndpoint is the Bot API used unless the identity names another one
+const defaultEndpoint = "https://api.telegram.org"
+
+// client calls the Telegram Bot API (https://core.telegram.org/bots/api)
+type client struct {
+	endpoint string
+	token    string
+	http     *http.Client
+}
+
+func newClient(endpoint, token string) *client {
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+	return &client{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		token:    token,
+		http:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// apiResponse is the envelope of all Bot API responses
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	ErrorCode   int             `json:"error_code"`
+	Description string          `json:"description"`
+	Parameters  struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+// call invokes a Bot API method and decodes its result (if not nil)
+func (c *client) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("Couldn't marshal request: %s", err)
+	}
+
+	// Create new HTTP request
+	endpoint := fmt.Sprintf("%s/bot%s/%s", c.endpoint, c.token, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("Couldn't create request: %s", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send request. The URL contains the bot token, so it must not end up
+	// in error messages.
+	resp, err := c.http.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("Couldn't call Telegram %s: %s", method, err)
+	}
+	defer resp.Body.Close()
+
+	var apiResp apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return fmt.Errorf("Couldn't unmarshalize response of %s (%s): %s", method, resp.Status, err)
+	}
+	if !apiResp.OK {
+		if apiResp.Parameters.RetryAfter > 0 {
+			return fmt.Errorf("Telegram rate limit exceeded (retry after %ds)", apiResp.Parameters.RetryAfter)
+		}
+		return fmt.Errorf("Telegram %s failed: %d %s", method, apiResp.ErrorCode, apiResp.Description)
+	}
+	if result != nil {
+		if err := json.Unmarshal(apiResp.Result, result); err != nil {
+			return fmt.Errorf("Couldn't unmarshalize result of %s: %s", method, err)
+		}
+	}
+	return nil
+}
+
+// User is a Telegram user or bot
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+// Chat is a Telegram chat (e.g. a channel)
+type Chat struct {
+	ID       int64  `json:"id"`
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Username string `json:"username"`
+}
+
+// Message is a message sent by the bot
+type Message struct {
+	MessageID int64 `json:"message_id"`
+	Chat      Chat  `json:"chat"`
+}
@@ -0,0 +1,126 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+// telegramMaxCharacters is the maximum length of a message
+const telegramMaxCharacters = 4096
+
+// SendMessage are the parameters of the sendMessage method
+type SendMessage struct {
+	ChatID                string `json:"chat_id"`
+	MessageID             int64  `json:"message_id,omitempty"`
+	Text                  string `json:"text"`
+	ParseMode             string `json:"parse_mode"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview,omitempty"`
+	DisableNotification   bool   `json:"disable_notification,omitempty"`
+}
+
+// ShareRepository sends messages to the chat (e.g. a channel) stored in the
+// identity. The identity holds the bot token as access token and the chat
+// ID as user ID.
+type ShareRepository struct {
+	conf   config.TelegramConfig
+	chatID string
+	client *client
+}
+
+func NewShareRepository(conf config.TelegramConfig, identity entity.IdentityProvider) *ShareRepository {
+	return &ShareRepository{
+		conf:   conf,
+		chatID: identity.UserID,
+		client: newClient(identity.Endpoint, identity.AccessToken),
+	}
+}
+
+// composeMessage formats an article as HTML: the linked title followed by
+// the comment
+func (t *ShareRepository) composeMessage(article entity.ArticleShare) (SendMessage, error) {
+	title := article.Title
+	if title == "" {
+		title = article.URL
+	}
+	if n := len([]rune(title + "\n\n" + article.Comment)); n > telegramMaxCharacters {
+		return SendMessage{}, fmt.Errorf("Post max characters exceeded: %d (allowed: %d)", n, telegramMaxCharacters)
+	}
+
+	text := fmt.Sprintf("<b><a href=\"%s\">%s</a></b>", html.EscapeString(article.URL), html.EscapeString(title))
+	if article.Comment != "" {
+		text += "\n\n" + html.EscapeString(article.Comment)
+	}
+	return SendMessage{
+		ChatID:                t.chatID,
+		Text:                  text,
+		ParseMode:             "HTML",
+		DisableWebPagePreview: t.conf.DisableLinkPreview,
+		DisableNotification:   t.conf.Silent,
+	}, nil
+}
+
+// PreviewArticle returns the sendMessage request which would be sent
+func (t *ShareRepository) PreviewArticle(ctx context.Context, article entity.ArticleShare) (entity.SharePreview, error) {
+	msg, err := t.composeMessage(article)
+	if err != nil {
+		return entity.SharePreview{}, err
+	}
+	return entity.SharePreview{
+		Text:      msg.Text,
+		MaxLength: telegramMaxCharacters,
+		Payload:   msg,
+	}, nil
+}
+
+// ShareArticle sends the article to the chat
+func (t *ShareRepository) ShareArticle(ctx context.Context, article entity.ArticleShare) (entity.ShareResult, error) {
+	msg, err := t.composeMessage(article)
+	if err != nil {
+		return entity.ShareResult{}, err
+	}
+
+	var sent Message
+	if err := t.client.call(ctx, "sendMessage", msg, &sent); err != nil {
+		return entity.ShareResult{}, err
+	}
+
+	result := entity.ShareResult{PostID: strconv.FormatInt(sent.MessageID, 10)}
+	// Only messages of public chats have a link
+	if sent.Chat.Username != "" {
+		result.PostURL = fmt.Sprintf("https://t.me/%s/%d", sent.Chat.Username, sent.MessageID)
+	}
+	return result, nil
+}
+
+// EditPost replaces the text of a sent message
+func (t *ShareRepository) EditPost(ctx context.Context, postID string, article entity.ArticleShare) error {
+	msg, err := t.composeMessage(article)
+	if err != nil {
+		return err
+	}
+	msg.MessageID, err = strconv.ParseInt(postID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("Invalid message ID: %s", postID)
+	}
+	msg.DisableNotification = false
+	return t.client.call(ctx, "editMessageText", msg, nil)
+}
+
+// DeletePost deletes a sent message. Telegram only allows deleting messages
+// during the first 48 hours.
+func (t *ShareRepository) DeletePost(ctx context.Context, postID string) error {
+	messageID, err := strconv.ParseInt(postID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("Invalid message ID: %s", postID)
+	}
+	params := map[string]interface{}{
+		"chat_id":    t.chatID,
+		"message_id": messageID,
+	}
+	return t.client.call(ctx, "deleteMessage", params, nil)
+}
@@ -0,0 +1,59 @@
+// Package telegram shares articles to Telegram chats and channels using a
+// bot. The bot token and the chat are kept in the identity store.
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/provider"
+	"github.com/dorneanu/gocial/internal/share"
+)
+
+// Provider returns the registry entry of Telegram
+func Provider(conf config.TelegramConfig) provider.Provider {
+	return provider.Provider{
+		ProviderInfo: entity.ProviderInfo{
+			Name:        "telegram",
+			DisplayName: "Telegram",
+			Capabilities: entity.ProviderCapabilities{
+				MaxLength: telegramMaxCharacters,
+				Edit:      true,
+				Delete:    true,
+			},
+		},
+		Connect: connect,
+		NewRepository: func(client config.ProviderConfig, id entity.IdentityProvider) (share.Repository, error) {
+			return NewShareRepository(conf, id), nil
+		},
+	}
+}
+
+// connect checks the bot token and the chat (an ID or @channelname). The
+// chat ID is stored instead of the channel name.
+func connect(ctx context.Context, id entity.IdentityProvider) (entity.IdentityProvider, error) {
+	if id.AccessToken == "" || id.UserID == "" {
+		return id, fmt.Errorf("Bot token and chat are required")
+	}
+	c := newClient(id.Endpoint, id.AccessToken)
+
+	var bot User
+	if err := c.call(ctx, "getMe", struct{}{}, &bot); err != nil {
+		return id, err
+	}
+	var chat Chat
+	if err := c.call(ctx, "getChat", map[string]string{"chat_id": id.UserID}, &chat); err != nil {
+		return id, err
+	}
+
+	id.UserID = strconv.FormatInt(chat.ID, 10)
+	id.UserName = chat.Title
+	if chat.Username != "" {
+		id.UserName = "@" + chat.Username
+	}
+	id.UserDescription = fmt.Sprintf("%s via @%s", chat.Type, bot.Username)
+	return id, nil
+}
@@ -0,0 +1,96 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+const testToken = "123456:test-token"
+
+// newFakeBotAPI returns a Bot API answering sendMessage for the chat
+// @gocialblog
+func newFakeBotAPI(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bot"+testToken+"/sendMessage" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"ok": false, "error_code": 401, "description": "Unauthorized"}`))
+			return
+		}
+
+		var msg SendMessage
+		if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&msg) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"ok": false, "error_code": 400, "description": "Bad Request: invalid request"}`))
+			return
+		}
+		switch {
+		case msg.ChatID != "@gocialblog":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"ok": false, "error_code": 400, "description": "Bad Request: chat not found"}`))
+			return
+		case strings.Contains(msg.Text, "flood"):
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"ok": false, "error_code": 429, "description": "Too Many Requests: retry after 30", "parameters": {"retry_after": 30}}`))
+			return
+		}
+		if msg.ParseMode != "HTML" || !msg.DisableNotification {
+			t.Errorf("message = %+v", msg)
+		}
+		w.Write([]byte(`{"ok": true, "result": {"message_id": 7, "chat": {"id": -100123, "type": "channel", "username": "gocialblog"}}}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestShareArticle(t *testing.T) {
+	srv := newFakeBotAPI(t)
+	conf := config.TelegramConfig{Silent: true}
+	repo := NewShareRepository(conf, entity.IdentityProvider{Endpoint: srv.URL, AccessToken: testToken, UserID: "@gocialblog"})
+	ctx := context.Background()
+
+	result, err := repo.ShareArticle(ctx, entity.ArticleShare{URL: "https://example.com/post", Title: "Post", Comment: "New post"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.PostID != "7" || result.PostURL != "https://t.me/gocialblog/7" {
+		t.Errorf("result = %+v", result)
+	}
+
+	_, err = repo.ShareArticle(ctx, entity.ArticleShare{URL: "https://example.com/post", Title: "Post", Comment: "flood"})
+	if err == nil || err.Error() != "Telegram rate limit exceeded (retry after 30s)" {
+		t.Errorf("rate limited share: %v", err)
+	}
+
+	other := NewShareRepository(conf, entity.IdentityProvider{Endpoint: srv.URL, AccessToken: testToken, UserID: "@unknown"})
+	_, err = other.ShareArticle(ctx, entity.ArticleShare{URL: "https://example.com/post", Title: "Post"})
+	if err == nil || err.Error() != "Telegram sendMessage failed: 400 Bad Request: chat not found" {
+		t.Errorf("share to unknown chat: %v", err)
+	}
+
+	wrongToken := NewShareRepository(conf, entity.IdentityProvider{Endpoint: srv.URL, AccessToken: "wrong", UserID: "@gocialblog"})
+	_, err = wrongToken.ShareArticle(ctx, entity.ArticleShare{URL: "https://example.com/post", Title: "Post"})
+	if err == nil || !strings.Contains(err.Error(), "401 Unauthorized") {
+		t.Errorf("share with wrong token: %v", err)
+	}
+}
+
+func TestCallHidesToken(t *testing.T) {
+	srv := newFakeBotAPI(t)
+	srv.Close()
+
+	c := newClient(srv.URL, testToken)
+	err := c.call(context.Background(), "sendMessage", SendMessage{ChatID: "@gocialblog"}, nil)
+	if err == nil {
+		t.Fatal("call to a closed server succeeded")
+	}
+	if strings.Contains(err.Error(), testToken) {
+		t.Errorf("error contains the bot token: %s", err)
+	}
+}
@@ -0,0 +1,160 @@
+package twitter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	gotwitter "github.com/dghubble/go-twitter/twitter"
+	"github.com/dghubble/oauth1"
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+const (
+	// https://developer.twitter.com/en/docs/counting-characters
+	twitterMaxCharacters = 280
+
+	// API URL for looking up Tweets (v2)
+	twitterTweetsAPI = "https://api.twitter.com/2/tweets"
+)
+
+// ShareRepository implements share.Repository
+type ShareRepository struct {
+	client     *gotwitter.Client
+	httpClient *http.Client
+}
+
+type Config struct {
+	ConsumerKey    string
+	ConsumerSecret string
+	AccessToken    string
+	AccessSecret   string
+}
+
+func NewShareRepository(twitterConf *Config) *ShareRepository {
+	// Create new twitter client based on the oauth config
+	//
+	// https://developer.twitter.com/en/docs/authentication/oauth-1-0a
+	config := oauth1.NewConfig(twitterConf.ConsumerKey, twitterConf.ConsumerSecret)
+	token := oauth1.NewToken(twitterConf.AccessToken, twitterConf.AccessSecret)
+	httpClient := config.Client(oauth1.NoContext, token)
+
+	// Twitter client
+	client := gotwitter.NewClient(httpClient)
+
+	return &ShareRepository{
+		client:     client,
+		httpClient: httpClient,
+	}
+}
+
+// Preview is the payload sent to Twitter
+type Preview struct {
+	Text string `json:"text"`
+}
+
+// composeTweet creates the text of a Tweet and checks its length
+func composeTweet(article entity.ArticleShare) (string, error) {
+	// Compose post
+	// TODO: also use article.Title
+	post := fmt.Sprintf("%s - %s", article.Comment, article.URL)
+
+	// Check post length in characters (not bytes)
+	if n := utf8.RuneCountInString(post); n > twitterMaxCharacters {
+		return "", fmt.Errorf("Post max characters exceeded: %d (allowed: %d)", n, twitterMaxCharacters)
+	}
+	return post, nil
+}
+
+// PreviewArticle returns the Tweet which would be sent
+func (t *ShareRepository) PreviewArticle(ctx context.Context, article entity.ArticleShare) (entity.SharePreview, error) {
+	post, err := composeTweet(article)
+	if err != nil {
+		return entity.SharePreview{}, err
+	}
+	return entity.SharePreview{
+		Text:      post,
+		MaxLength: twitterMaxCharacters,
+		Payload:   Preview{Text: post},
+	}, nil
+}
+
+// ShareArticle sends a new Tweet
+func (t *ShareRepository) ShareArticle(ctx context.Context, article entity.ArticleShare) (entity.ShareResult, error) {
+	post, err := composeTweet(article)
+	if err != nil {
+		return entity.ShareResult{}, err
+	}
+
+	// Send a Tweet
+	tweet, _, err := t.client.Statuses.Update(post, nil)
+	if err != nil {
+		return entity.ShareResult{}, fmt.Errorf("Couldn't send tweet: %s", err)
+	}
+
+	result := entity.ShareResult{PostID: tweet.IDStr}
+	if tweet.User != nil {
+		result.PostURL = fmt.Sprintf("https://twitter.com/%s/status/%s", tweet.User.ScreenName, tweet.IDStr)
+	}
+	return result, nil
+}
+
+// DeletePost destroys a Tweet
+func (t *ShareRepository) DeletePost(ctx context.Context, postID string) error {
+	id, err := strconv.ParseInt(postID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("Invalid tweet ID: %s", postID)
+	}
+
+	if _, _, err := t.client.Statuses.Destroy(id, nil); err != nil {
+		return fmt.Errorf("Couldn't delete tweet: %s", err)
+	}
+	return nil
+}
+
+// twitterTweetMetrics is the response of the v2 tweets lookup
+//
+// Check out https://developer.twitter.com/en/docs/twitter-api/metrics
+type twitterTweetMetrics struct {
+	Data struct {
+		PublicMetrics struct {
+			RetweetCount int `json:"retweet_count"`
+			ReplyCount   int `json:"reply_count"`
+			LikeCount    int `json:"like_count"`
+			QuoteCount   int `json:"quote_count"`
+		} `json:"public_metrics"`
+	} `json:"data"`
+}
+
+// GetMetrics fetches the public metrics of a Tweet
+func (t *ShareRepository) GetMetrics(ctx context.Context, postID string) (entity.PostMetrics, error) {
+	endpoint := fmt.Sprintf("%s/%s?tweet.fields=public_metrics", twitterTweetsAPI, postID)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return entity.PostMetrics{}, fmt.Errorf("Couldn't create request: %s", err)
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return entity.PostMetrics{}, fmt.Errorf("Couldn't fetch tweet metrics: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return entity.PostMetrics{}, fmt.Errorf("Couldn't fetch tweet metrics: %s", resp.Status)
+	}
+
+	var metrics twitterTweetMetrics
+	if err := json.NewDecoder(resp.Body).Decode(&metrics); err != nil {
+		return entity.PostMetrics{}, fmt.Errorf("Couldn't unmarshalize tweet metrics: %s", err)
+	}
+	m := metrics.Data.PublicMetrics
+	return entity.PostMetrics{
+		Likes:    m.LikeCount,
+		Reposts:  m.RetweetCount + m.QuoteCount,
+		Comments: m.ReplyCount,
+	}, nil
+}
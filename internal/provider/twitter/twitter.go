@@ -0,0 +1,38 @@
+// Package twitter shares articles as Tweets
+package twitter
+
+import (
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/provider"
+	"github.com/dorneanu/gocial/internal/share"
+	"github.com/markbates/goth"
+	gothtwitter "github.com/markbates/goth/providers/twitter"
+)
+
+func init() {
+	provider.Register(provider.Provider{
+		ProviderInfo: entity.ProviderInfo{
+			Name:        "twitter",
+			DisplayName: "Twitter",
+			Capabilities: entity.ProviderCapabilities{
+				MaxLength: twitterMaxCharacters,
+				Delete:    true,
+				Metrics:   true,
+			},
+		},
+		OAuth: func(clientID, clientSecret, callbackURL string, scopes []string) goth.Provider {
+			return gothtwitter.New(clientID, clientSecret, callbackURL)
+		},
+		OAuth1: true,
+		// The consumer key of the OAuth client is needed for every request
+		NewRepository: func(client config.ProviderConfig, identity entity.IdentityProvider) (share.Repository, error) {
+			return NewShareRepository(&Config{
+				ConsumerKey:    client.ClientID,
+				ConsumerSecret: client.ClientSecret,
+				AccessToken:    identity.AccessToken,
+				AccessSecret:   identity.AccessTokenSecret,
+			}), nil
+		},
+	})
+}
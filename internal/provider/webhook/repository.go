@@ -0,0 +1,228 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+const (
+	// defaultTimeout is used if the webhook has no timeout configured
+	defaultTimeout = 10 * time.Second
+
+	// defaultSignatureHeader carries the HMAC-SHA256 signature of the payload
+	defaultSignatureHeader = "X-Gocial-Signature"
+
+	// defaultBody is sent by JSON webhooks without a body template
+	defaultBody = `{"url": {{ json .URL }}, "title": {{ json .Title }}, "comment": {{ json .Comment }}, "target": {{ json .Target }}, "shared_at": {{ json .SharedAt }}}`
+)
+
+// defaultFields are sent by form webhooks without field templates
+var defaultFields = map[string]string{
+	"url":     "{{ .URL }}",
+	"title":   "{{ .Title }}",
+	"comment": "{{ .Comment }}",
+}
+
+// funcs are available in body and field templates
+var funcs = template.FuncMap{
+	// json encodes a value, e.g. {"text": {{ json .Comment }}}
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// templateData is passed to the body and field templates
+type templateData struct {
+	entity.ArticleShare
+	// Target is the name of the webhook
+	Target   string
+	SharedAt time.Time
+}
+
+// Repository sends shared articles to a webhook
+type Repository struct {
+	conf   config.WebhookConfig
+	client *http.Client
+}
+
+// Preview is the request which would be sent to the webhook. Only the host
+// of the URL is shown since path and query often contain a token.
+type Preview struct {
+	Method      string `json:"method"`
+	Host        string `json:"host"`
+	ContentType string `json:"content_type"`
+	Body        string `json:"body"`
+}
+
+func NewRepository(conf config.WebhookConfig) *Repository {
+	timeout := conf.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &Repository{
+		conf:   conf,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// payload renders the request body of an article
+func (r *Repository) payload(article entity.ArticleShare) (contentType string, body []byte, err error) {
+	data := templateData{
+		ArticleShare: article,
+		Target:       r.conf.Name,
+		SharedAt:     time.Now().UTC(),
+	}
+
+	if r.conf.Format == "form" {
+		fields := r.conf.Fields
+		if len(fields) == 0 {
+			fields = defaultFields
+		}
+		values := url.Values{}
+		for name, text := range fields {
+			value, err := render(text, data)
+			if err != nil {
+				return "", nil, fmt.Errorf("Couldn't render field %s: %s", name, err)
+			}
+			values.Set(name, value)
+		}
+		return "application/x-www-form-urlencoded", []byte(values.Encode()), nil
+	}
+
+	text := r.conf.Body
+	if text == "" {
+		text = defaultBody
+	}
+	rendered, err := render(text, data)
+	if err != nil {
+		return "", nil, fmt.Errorf("Couldn't render body: %s", err)
+	}
+	if !json.Valid([]byte(rendered)) {
+		return "", nil, fmt.Errorf("Rendered body isn't valid JSON: %s", rendered)
+	}
+	return "application/json", []byte(rendered), nil
+}
+
+// method returns the HTTP method of the webhook
+func (r *Repository) method() string {
+	if r.conf.Method == "" {
+		return http.MethodPost
+	}
+	return strings.ToUpper(r.conf.Method)
+}
+
+// host returns the host of the webhook URL
+func (r *Repository) host() string {
+	u, err := url.Parse(r.conf.URL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// PreviewArticle returns the request which would be sent to the webhook
+func (r *Repository) PreviewArticle(ctx context.Context, article entity.ArticleShare) (entity.SharePreview, error) {
+	contentType, body, err := r.payload(article)
+	if err != nil {
+		return entity.SharePreview{}, err
+	}
+	return entity.SharePreview{
+		Text: article.Comment,
+		Payload: Preview{
+			Method:      r.method(),
+			Host:        r.host(),
+			ContentType: contentType,
+			Body:        string(body),
+		},
+	}, nil
+}
+
+// ShareArticle sends the article to the webhook
+func (r *Repository) ShareArticle(ctx context.Context, article entity.ArticleShare) (entity.ShareResult, error) {
+	contentType, body, err := r.payload(article)
+	if err != nil {
+		return entity.ShareResult{}, err
+	}
+
+	// Create new HTTP request
+	req, err := http.NewRequestWithContext(ctx, r.method(), r.conf.URL, bytes.NewReader(body))
+	if err != nil {
+		return entity.ShareResult{}, fmt.Errorf("Couldn't create request: %s", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "gocial")
+	for k, v := range r.conf.Headers {
+		req.Header.Set(k, v)
+	}
+	if r.conf.Secret != "" {
+		header := r.conf.SignatureHeader
+		if header == "" {
+			header = defaultSignatureHeader
+		}
+		req.Header.Set(header, Sign(r.conf.Secret, body))
+	}
+
+	// Send request
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return entity.ShareResult{}, fmt.Errorf("Couldn't call webhook %s: %s", r.conf.Name, err)
+	}
+	defer resp.Body.Close()
+
+	if !r.expected(resp.StatusCode) {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return entity.ShareResult{}, fmt.Errorf("Webhook %s returned %s: %s", r.conf.Name, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return entity.ShareResult{}, nil
+}
+
+// expected checks the status code of a webhook response
+func (r *Repository) expected(status int) bool {
+	if len(r.conf.ExpectedStatus) == 0 {
+		return status >= 200 && status <= 299
+	}
+	for _, s := range r.conf.ExpectedStatus {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign returns the HMAC-SHA256 signature of a payload as sent in the
+// signature header ("sha256=<hex digest>")
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// render executes a text template with the given data
+func render(text string, data interface{}) (string, error) {
+	tmpl, err := template.New("webhook").Funcs(funcs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't parse template: %s", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("Couldn't execute template: %s", err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,29 @@
+// Package webhook provides share repositories sending articles to
+// configured HTTP callbacks (e.g. automation tools or internal dashboards)
+package webhook
+
+import (
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/provider"
+	"github.com/dorneanu/gocial/internal/share"
+)
+
+// Provider returns the registry entry of a webhook target. Webhooks carry
+// their credentials in the configuration, so no identity is required.
+func Provider(conf config.WebhookConfig) provider.Provider {
+	displayName := conf.DisplayName
+	if displayName == "" {
+		displayName = conf.Name
+	}
+	return provider.Provider{
+		ProviderInfo: entity.ProviderInfo{
+			Name:        conf.Name,
+			DisplayName: displayName,
+		},
+		NoIdentity: true,
+		NewRepository: func(client config.ProviderConfig, id entity.IdentityProvider) (share.Repository, error) {
+			return NewRepository(conf), nil
+		},
+	}
+}
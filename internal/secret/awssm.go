@@ -0,0 +1,184 @@
+package secret
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSSecretsManager resolves "awssm:secret-id" (or "awssm:secret-id#key" for
+// secrets stored as JSON) using the GetSecretValue API. Credentials are
+// taken from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+// as provided to Lambda functions.
+type AWSSecretsManager struct {
+	Region string
+	// Endpoint overrides the regional endpoint (e.g. for LocalStack)
+	Endpoint string
+	Client   *http.Client
+}
+
+func (s AWSSecretsManager) Resolve(ctx context.Context, ref string) (string, error) {
+	name, key := splitKey(ref)
+
+	region := s.Region
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		return "", fmt.Errorf("No AWS region configured")
+	}
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com/", region)
+	}
+	accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
+	secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return "", fmt.Errorf("No AWS credentials found")
+	}
+
+	body, err := json.Marshal(map[string]string{"SecretId": name})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	if token := os.Getenv("AWS_SESSION_TOKEN"); token != "" {
+		req.Header.Set("X-Amz-Security-Token", token)
+	}
+	signV4(req, body, region, "secretsmanager", accessKey, secretKey, time.Now().UTC())
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GetSecretValue failed: %s: %s", resp.Status, data)
+	}
+
+	var result struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return "", fmt.Errorf("Couldn't parse response: %s", err)
+	}
+	return selectKey(result.SecretString, key)
+}
+
+// signV4 signs req using AWS Signature Version 4
+func signV4(req *http.Request, body []byte, region, service, accessKey, secretKey string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	// Canonical request
+	headers := map[string]string{"host": req.URL.Host}
+	names := []string{"host"}
+	for name := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(req.Header.Get(name))
+			names = append(names, lower)
+		}
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hexSHA256(body),
+	}, "\n")
+
+	// String to sign
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+// canonicalQuery encodes q sorted by key and value
+func canonicalQuery(q url.Values) string {
+	encoded := make(map[string][]string)
+	keys := make([]string, 0, len(q))
+	for key, values := range q {
+		k := uriEncode(key)
+		keys = append(keys, k)
+		for _, v := range values {
+			encoded[k] = append(encoded[k], uriEncode(v))
+		}
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0)
+	for _, k := range keys {
+		sort.Strings(encoded[k])
+		for _, v := range encoded[k] {
+			pairs = append(pairs, k+"="+v)
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes everything but unreserved characters
+func uriEncode(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
+
+func hexSHA256(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
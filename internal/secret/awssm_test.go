@@ -0,0 +1,169 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test vectors of the AWS Signature Version 4 test suite and documentation
+func TestSignV4(t *testing.T) {
+	const (
+		accessKey = "AKIDEXAMPLE"
+		secretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	)
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		method    string
+		url       string
+		headers   map[string]string
+		body      string
+		service   string
+		signed    string
+		signature string
+	}{
+		{
+			name:      "get-vanilla",
+			method:    "GET",
+			url:       "https://example.amazonaws.com/",
+			service:   "service",
+			signed:    "host;x-amz-date",
+			signature: "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:      "get-vanilla-query-order-key-case",
+			method:    "GET",
+			url:       "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			service:   "service",
+			signed:    "host;x-amz-date",
+			signature: "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name:      "post-x-www-form-urlencoded",
+			method:    "POST",
+			url:       "https://example.amazonaws.com/",
+			headers:   map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			body:      "Param1=value1",
+			service:   "service",
+			signed:    "content-type;host;x-amz-date",
+			signature: "ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+		{
+			name:      "iam-list-users",
+			method:    "GET",
+			url:       "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08",
+			headers:   map[string]string{"Content-Type": "application/x-www-form-urlencoded; charset=utf-8"},
+			service:   "iam",
+			signed:    "content-type;host;x-amz-date",
+			signature: "5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7",
+		},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range tt.headers {
+			req.Header.Set(k, v)
+		}
+		signV4(req, []byte(tt.body), "us-east-1", tt.service, accessKey, secretKey, now)
+
+		want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/" + tt.service + "/aws4_request, " +
+			"SignedHeaders=" + tt.signed + ", Signature=" + tt.signature
+		if got := req.Header.Get("Authorization"); got != want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.name, got, want)
+		}
+		if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+			t.Errorf("%s: X-Amz-Date = %q", tt.name, got)
+		}
+	}
+}
+
+func TestCanonicalQuery(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.com/?b=2&a=x+y&a=1&c=%2F&a-b=~", nil)
+	want := "a=1&a=x%20y&a-b=~&b=2&c=%2F"
+	if got := canonicalQuery(req.URL.Query()); got != want {
+		t.Errorf("canonicalQuery = %q, want %q", got, want)
+	}
+}
+
+func TestAWSSecretsManager(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "session")
+
+	secrets := map[string]string{
+		"gocial/jwt":  "s3cret",
+		"gocial/json": `{"client_secret": "abc"}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" {
+			t.Errorf("Unexpected target: %s", r.Header.Get("X-Amz-Target"))
+		}
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") ||
+			!strings.Contains(auth, "/eu-central-1/secretsmanager/aws4_request") ||
+			!strings.Contains(auth, "x-amz-security-token") {
+			t.Errorf("Unexpected authorization: %s", auth)
+		}
+		if r.Header.Get("X-Amz-Security-Token") != "session" {
+			t.Errorf("Missing session token")
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		var input struct{ SecretId string }
+		if err := json.Unmarshal(body, &input); err != nil {
+			t.Errorf("Invalid request body: %s", body)
+		}
+		value, ok := secrets[input.SecretId]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type": "ResourceNotFoundException"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"SecretString": value})
+	}))
+	defer srv.Close()
+
+	sm := AWSSecretsManager{Region: "eu-central-1", Endpoint: srv.URL + "/"}
+	tests := []struct {
+		ref  string
+		want string
+		err  string
+	}{
+		{ref: "gocial/jwt", want: "s3cret"},
+		{ref: "gocial/json#client_secret", want: "abc"},
+		{ref: "gocial/json#missing", err: `Secret has no key "missing"`},
+		{ref: "gocial/missing", err: "ResourceNotFoundException"},
+	}
+	for _, tt := range tests {
+		got, err := sm.Resolve(context.Background(), tt.ref)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Resolve(%q): got error %v, want %q", tt.ref, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Resolve(%q): %s", tt.ref, err)
+		} else if got != tt.want {
+			t.Errorf("Resolve(%q) = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestAWSSecretsManagerWithoutCredentials(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	sm := AWSSecretsManager{Region: "eu-central-1", Endpoint: "http://127.0.0.1:1/"}
+	if _, err := sm.Resolve(context.Background(), "gocial/jwt"); err == nil {
+		t.Error("Expected an error without credentials")
+	}
+}
@@ -0,0 +1,39 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// LocalStore resolves secrets from a JSON file mapping secret names to
+// values. It stands in for remote secret managers in tests and development.
+// Values may be strings or objects; "name#key" selects a single field.
+type LocalStore struct {
+	Path string
+}
+
+func (s LocalStore) Resolve(ctx context.Context, ref string) (string, error) {
+	data, err := ioutil.ReadFile(s.Path)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't read local secrets: %s", err)
+	}
+	secrets := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return "", fmt.Errorf("Couldn't parse local secrets: %s", err)
+	}
+
+	name, key := splitKey(ref)
+	raw, ok := secrets[name]
+	if !ok {
+		return "", fmt.Errorf("Secret not found: %s", name)
+	}
+
+	// Plain strings may hold JSON themselves (like AWS secret strings)
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		value = string(raw)
+	}
+	return selectKey(value, key)
+}
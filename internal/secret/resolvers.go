@@ -0,0 +1,57 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Env resolves "env:NAME" from the environment
+var Env = ResolverFunc(func(ctx context.Context, name string) (string, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok || v == "" {
+		return "", fmt.Errorf("Environment variable %s is not set", name)
+	}
+	return v, nil
+})
+
+// File resolves "file:/path" to the content of the file without trailing
+// newlines (e.g. Docker or Kubernetes secrets)
+var File = ResolverFunc(func(ctx context.Context, path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+})
+
+// Exec resolves "exec:command args..." to the output of the command (e.g.
+// "exec:pass show gocial/jwt"). The command is not run by a shell.
+type Exec struct {
+	Timeout time.Duration
+}
+
+func (e Exec) Resolve(ctx context.Context, command string) (string, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return "", fmt.Errorf("No command given")
+	}
+
+	if e.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("Command %s failed: %s", args[0], err)
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
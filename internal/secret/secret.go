@@ -0,0 +1,75 @@
+// Package secret resolves secret references used in the configuration, e.g.
+// "env:LINKEDIN_CLIENT_SECRET" or "file:/run/secrets/jwt".
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Resolver returns the secret a reference points to. The reference is
+// passed without its scheme.
+type Resolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// ResolverFunc is a function implementing Resolver
+type ResolverFunc func(ctx context.Context, ref string) (string, error)
+
+func (f ResolverFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+// Resolvers maps schemes (e.g. "env") to their resolvers
+type Resolvers map[string]Resolver
+
+// Resolve resolves value if it is a reference ("<scheme>:<ref>") with a
+// known scheme. All other values are returned unchanged.
+func (r Resolvers) Resolve(ctx context.Context, value string) (string, error) {
+	i := strings.Index(value, ":")
+	if i <= 0 {
+		return value, nil
+	}
+	resolver, ok := r[value[:i]]
+	if !ok {
+		return value, nil
+	}
+
+	s, err := resolver.Resolve(ctx, value[i+1:])
+	if err != nil {
+		return "", fmt.Errorf("Couldn't resolve %s reference: %s", value[:i], err)
+	}
+	return s, nil
+}
+
+// splitKey splits a reference like "name#key" into name and key
+func splitKey(ref string) (string, string) {
+	if i := strings.LastIndex(ref, "#"); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, ""
+}
+
+// selectKey returns the field key of a secret stored as JSON object. The
+// secret is returned unchanged if no key is given.
+func selectKey(secret, key string) (string, error) {
+	if key == "" {
+		return secret, nil
+	}
+
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("Secret is not a JSON object")
+	}
+	v, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("Secret has no key %q", key)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("Key %q is not a string", key)
+	}
+	return s, nil
+}
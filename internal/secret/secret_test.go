@@ -0,0 +1,72 @@
+package secret
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolvers(t *testing.T) {
+	dir := t.TempDir()
+	local := filepath.Join(dir, "secrets.json")
+	data := `{"jwt": "s3cret", "db": {"user": "gocial", "port": 5432}, "aws": "{\"key\": \"from-string\"}"}`
+	if err := ioutil.WriteFile(local, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOCIAL_TEST_SECRET", "from-env")
+
+	resolvers := Resolvers{
+		"env":   Env,
+		"local": LocalStore{Path: local},
+	}
+	tests := []struct {
+		value string
+		want  string
+		err   string
+	}{
+		{value: "plain value", want: "plain value"},
+		{value: "https://example.com", want: "https://example.com"},
+		{value: ":no-scheme", want: ":no-scheme"},
+		{value: "env:GOCIAL_TEST_SECRET", want: "from-env"},
+		{value: "env:GOCIAL_TEST_MISSING", err: "Couldn't resolve env reference"},
+		{value: "local:jwt", want: "s3cret"},
+		{value: "local:db#user", want: "gocial"},
+		{value: "local:aws#key", want: "from-string"},
+		{value: "local:db#port", err: `Key "port" is not a string`},
+		{value: "local:db#missing", err: `Secret has no key "missing"`},
+		{value: "local:jwt#key", err: "Secret is not a JSON object"},
+		{value: "local:missing", err: "Secret not found: missing"},
+	}
+	for _, tt := range tests {
+		got, err := resolvers.Resolve(context.Background(), tt.value)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Resolve(%q): got error %v, want %q", tt.value, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Resolve(%q): %s", tt.value, err)
+		} else if got != tt.want {
+			t.Errorf("Resolve(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSplitKey(t *testing.T) {
+	tests := []struct {
+		ref, name, key string
+	}{
+		{"secret/data/gocial", "secret/data/gocial", ""},
+		{"secret/data/gocial#jwt", "secret/data/gocial", "jwt"},
+		{"a#b#c", "a#b", "c"},
+	}
+	for _, tt := range tests {
+		name, key := splitKey(tt.ref)
+		if name != tt.name || key != tt.key {
+			t.Errorf("splitKey(%q) = %q, %q, want %q, %q", tt.ref, name, key, tt.name, tt.key)
+		}
+	}
+}
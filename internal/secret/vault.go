@@ -0,0 +1,74 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Vault resolves "vault:path#key" from a HashiCorp Vault KV secrets engine
+// (e.g. "vault:secret/data/gocial#jwt" for KV version 2). The key defaults
+// to "value".
+type Vault struct {
+	Address string
+	Token   string
+	Client  *http.Client
+}
+
+func (v Vault) Resolve(ctx context.Context, ref string) (string, error) {
+	path, key := splitKey(ref)
+	if key == "" {
+		key = "value"
+	}
+	if v.Address == "" {
+		return "", fmt.Errorf("No Vault address configured")
+	}
+
+	url := fmt.Sprintf("%s/v1/%s", strings.TrimSuffix(v.Address, "/"), strings.TrimPrefix(path, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+
+	client := v.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Reading %s failed: %s", path, resp.Status)
+	}
+
+	// KV version 2 nests the secret in data.data
+	var result struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return "", fmt.Errorf("Couldn't parse response: %s", err)
+	}
+	fields := result.Data
+	if nested, ok := fields["data"].(map[string]interface{}); ok {
+		if _, ok := fields["metadata"]; ok {
+			fields = nested
+		}
+	}
+
+	s, ok := fields[key].(string)
+	if !ok {
+		return "", fmt.Errorf("Secret %s has no key %q", path, key)
+	}
+	return s, nil
+}
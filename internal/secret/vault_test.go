@@ -0,0 +1,69 @@
+package secret
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVault(t *testing.T) {
+	responses := map[string]string{
+		// KV version 1
+		"/v1/kv/gocial": `{"data": {"value": "v1-value", "jwt": "v1-jwt", "port": 5432}}`,
+		// KV version 2
+		"/v1/secret/data/gocial": `{"data": {"data": {"value": "v2-value", "jwt": "v2-jwt"}, "metadata": {"version": 3}}}`,
+		// KV version 1 secret with a field called "data"
+		"/v1/kv/nested": `{"data": {"data": {"value": "inner"}, "value": "outer"}}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		ref   string
+		token string
+		want  string
+		err   string
+	}{
+		{ref: "kv/gocial", want: "v1-value"},
+		{ref: "/kv/gocial#jwt", want: "v1-jwt"},
+		{ref: "kv/gocial#port", err: `has no key "port"`},
+		{ref: "secret/data/gocial", want: "v2-value"},
+		{ref: "secret/data/gocial#jwt", want: "v2-jwt"},
+		{ref: "secret/data/gocial#missing", err: `has no key "missing"`},
+		{ref: "kv/nested", want: "outer"},
+		{ref: "kv/missing", err: "404"},
+		{ref: "kv/gocial", token: "wrong", err: "403"},
+	}
+	for _, tt := range tests {
+		token := tt.token
+		if token == "" {
+			token = "token"
+		}
+		v := Vault{Address: srv.URL + "/", Token: token}
+		got, err := v.Resolve(context.Background(), tt.ref)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Resolve(%q): got error %v, want %q", tt.ref, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Resolve(%q): %s", tt.ref, err)
+		} else if got != tt.want {
+			t.Errorf("Resolve(%q) = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+}
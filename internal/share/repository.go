@@ -2,10 +2,46 @@ package share
 
 import (
 	"context"
+	"errors"
 
 	"github.com/dorneanu/gocial/internal/entity"
 )
 
+// ErrNotSupported is returned when a repository lacks an optional capability
+var ErrNotSupported = errors.New("Provider doesn't support this operation")
+
+// ErrDuplicate is returned when a provider rejects an article which was
+// already shared
+var ErrDuplicate = errors.New("Article was already shared")
+
+// ErrDeleted is returned when a share was already deleted
+var ErrDeleted = errors.New("Share was already deleted")
+
 type Repository interface {
-	ShareArticle(context.Context, entity.ArticleShare) error
+	ShareArticle(context.Context, entity.ArticleShare) (entity.ShareResult, error)
+}
+
+// RepositoryFactory returns the repository of an identity's provider
+type RepositoryFactory func(entity.IdentityProvider) (Repository, error)
+
+// Previewer is implemented by repositories which can return the exact
+// payload they would send without calling the remote API
+type Previewer interface {
+	PreviewArticle(context.Context, entity.ArticleShare) (entity.SharePreview, error)
+}
+
+// MetricsRepository is implemented by repositories which can fetch
+// the engagement (likes, reposts, comments) of a published post
+type MetricsRepository interface {
+	GetMetrics(context.Context, string) (entity.PostMetrics, error)
+}
+
+// PostDeleter is implemented by repositories which can delete published posts
+type PostDeleter interface {
+	DeletePost(context.Context, string) error
+}
+
+// PostEditor is implemented by repositories which can edit published posts
+type PostEditor interface {
+	EditPost(context.Context, string, entity.ArticleShare) error
 }
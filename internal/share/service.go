@@ -2,29 +2,253 @@ package share
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
-	"os"
+	"strings"
+	"time"
 
+	"github.com/dorneanu/gocial/internal/analytics"
+	"github.com/dorneanu/gocial/internal/config"
 	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/history"
+	"github.com/dorneanu/gocial/internal/shortener"
 )
 
 type Service interface {
-	ShareArticle(entity.ArticleShare, Repository) error
+	ShareArticle(entity.ArticleShare, entity.IdentityProvider) (entity.ShareEntry, error)
+	PreviewArticle(entity.ArticleShare, entity.IdentityProvider) (entity.SharePreview, error)
 	ShareComment(entity.CommentShare, Repository) error
+	GetShare(string) (entity.ShareEntry, error)
+	DeleteShare(entity.ShareEntry, entity.IdentityProvider) error
+	EditShare(entity.ShareEntry, entity.ArticleShare, entity.IdentityProvider) error
 	GetShareRepo(entity.IdentityProvider) (Repository, error)
 }
 
-type shareService struct{}
+// ServiceConfig holds the dependencies of the share service
+type ServiceConfig struct {
+	// Repositories creates the repository of an identity's provider
+	Repositories RepositoryFactory
+	UTM          config.UTMConfig
+	Shortener    shortener.Shortener
+	History      history.Repository
+	// Analytics enables tracking links if set
+	Analytics analytics.Service
+}
+
+type shareService struct {
+	repositories RepositoryFactory
+	utm          config.UTMConfig
+	shortener    shortener.Shortener
+	history      history.Repository
+	analytics    analytics.Service
+}
+
+func NewShareService(conf ServiceConfig) Service {
+	return shareService{
+		repositories: conf.Repositories,
+		utm:          conf.UTM,
+		shortener:    conf.Shortener,
+		history:      conf.History,
+		analytics:    conf.Analytics,
+	}
+}
+
+// ShareArticle shares an article via the repository of the given identity
+func (s shareService) ShareArticle(article entity.ArticleShare, identity entity.IdentityProvider) (entity.ShareEntry, error) {
+	repo, err := s.GetShareRepo(identity)
+	if err != nil {
+		return entity.ShareEntry{}, err
+	}
+
+	// Rewrite URL
+	originalURL := article.URL
+	article.OriginalURL = originalURL
+	if !article.DisableUTM {
+		article.URL, err = tagURL(article.URL, identity.Provider, s.utm)
+		if err != nil {
+			return entity.ShareEntry{}, err
+		}
+	}
+	longURL := article.URL
+	shareID := newShareID()
+
+	// Replace URL by a tracking link for this share and provider
+	var trackingURL string
+	if s.analytics != nil {
+		trackingURL, err = s.analytics.NewTrackingLink(shareID, identity.Provider, longURL)
+		if err != nil {
+			return entity.ShareEntry{}, err
+		}
+		article.URL = trackingURL
+	}
+
+	// Shorten URL before the post text gets composed
+	var shortURL string
+	if s.shortener != nil {
+		shortURL, err = s.shortener.Shorten(context.Background(), article.URL)
+		if err != nil {
+			return entity.ShareEntry{}, err
+		}
+		article.URL = shortURL
+	}
+
+	// Send article to repository
+	result, err := repo.ShareArticle(context.Background(), article)
+	if err != nil {
+		return entity.ShareEntry{}, err
+	}
+
+	entry := entity.ShareEntry{
+		ID:          shareID,
+		Provider:    identity.Provider,
+		Account:     identity.UserName,
+		AccountID:   identity.UserID,
+		URL:         longURL,
+		OriginalURL: originalURL,
+		ShortURL:    shortURL,
+		TrackingURL: trackingURL,
+		PostID:      result.PostID,
+		PostURL:     result.PostURL,
+		Title:       article.Title,
+		Comment:     article.Comment,
+		Tags:        splitTags(article.Tags),
+		SharedAt:    time.Now(),
+		Deliveries:  result.Deliveries,
+	}
+
+	// Keep track of shared articles
+	if s.history == nil {
+		return entry, nil
+	}
+	return entry, s.history.Add(entry)
+}
+
+// GetShare returns a share from the history
+func (s shareService) GetShare(id string) (entity.ShareEntry, error) {
+	if s.history == nil {
+		return entity.ShareEntry{}, fmt.Errorf("No share history configured")
+	}
+	return s.history.GetByID(id)
+}
+
+// DeleteShare deletes a published post and marks it as deleted in the history
+func (s shareService) DeleteShare(entry entity.ShareEntry, identity entity.IdentityProvider) error {
+	if entry.DeletedAt != nil {
+		return ErrDeleted
+	}
+	repo, err := s.GetShareRepo(identity)
+	if err != nil {
+		return err
+	}
+	deleter, ok := repo.(PostDeleter)
+	if !ok {
+		return ErrNotSupported
+	}
+	if entry.PostID == "" {
+		return fmt.Errorf("Share has no post ID: %s", entry.ID)
+	}
+
+	if err := deleter.DeletePost(context.Background(), entry.PostID); err != nil {
+		return err
+	}
 
-func NewShareService() Service {
-	return shareService{}
+	now := time.Now()
+	entry.DeletedAt = &now
+	if s.history == nil {
+		return nil
+	}
+	return s.history.Update(entry)
 }
 
-// ShareArticle shares an article using the specified repository
-func (s shareService) ShareArticle(article entity.ArticleShare, repo Repository) error {
-	// Send article to each available repository
-	err := repo.ShareArticle(context.Background(), article)
-	return err
+// EditShare changes the comment and title of a published post
+func (s shareService) EditShare(entry entity.ShareEntry, article entity.ArticleShare, identity entity.IdentityProvider) error {
+	if entry.DeletedAt != nil {
+		return ErrDeleted
+	}
+	repo, err := s.GetShareRepo(identity)
+	if err != nil {
+		return err
+	}
+	editor, ok := repo.(PostEditor)
+	if !ok {
+		return ErrNotSupported
+	}
+	if entry.PostID == "" {
+		return fmt.Errorf("Share has no post ID: %s", entry.ID)
+	}
+
+	// The URL of a published post stays the same
+	article.URL = entry.URL
+	article.OriginalURL = entry.OriginalURL
+	if article.OriginalURL == "" {
+		article.OriginalURL = entry.URL
+	}
+	if entry.ShortURL != "" {
+		article.URL = entry.ShortURL
+	} else if entry.TrackingURL != "" {
+		article.URL = entry.TrackingURL
+	}
+
+	if err := editor.EditPost(context.Background(), entry.PostID, article); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	entry.Title = article.Title
+	entry.Comment = article.Comment
+	entry.EditedAt = &now
+	if s.history == nil {
+		return nil
+	}
+	return s.history.Update(entry)
+}
+
+// PreviewArticle runs the share pipeline without calling the remote API and
+// without storing tracking or short links. It returns the payload the
+// provider would receive.
+func (s shareService) PreviewArticle(article entity.ArticleShare, identity entity.IdentityProvider) (entity.SharePreview, error) {
+	repo, err := s.GetShareRepo(identity)
+	if err != nil {
+		return entity.SharePreview{}, err
+	}
+	previewer, ok := repo.(Previewer)
+	if !ok {
+		return entity.SharePreview{}, ErrNotSupported
+	}
+
+	// Rewrite URL
+	originalURL := article.URL
+	article.OriginalURL = originalURL
+	if !article.DisableUTM {
+		article.URL, err = tagURL(article.URL, identity.Provider, s.utm)
+		if err != nil {
+			return entity.SharePreview{}, err
+		}
+	}
+
+	// Tracking and short links look like the real ones but aren't stored
+	if s.analytics != nil {
+		article.URL, err = s.analytics.PreviewTrackingLink()
+		if err != nil {
+			return entity.SharePreview{}, err
+		}
+	}
+	if s.shortener != nil {
+		article.URL, err = shortener.Preview(context.Background(), s.shortener, article.URL)
+		if err != nil {
+			return entity.SharePreview{}, err
+		}
+	}
+
+	preview, err := previewer.PreviewArticle(context.Background(), article)
+	if err != nil {
+		return entity.SharePreview{}, err
+	}
+	preview.Provider = identity.Provider
+	preview.URL = article.URL
+	preview.OriginalURL = originalURL
+	return preview, nil
 }
 
 // TODO: Implement ShareComment ...
@@ -32,21 +256,31 @@ func (s shareService) ShareComment(comment entity.CommentShare, repo Repository)
 	return nil
 }
 
+// GetShareRepo returns the repository of the identity's provider
 func (s shareService) GetShareRepo(identity entity.IdentityProvider) (Repository, error) {
-	if identity.Provider == "twitter" { // twitter
-		twitterConfig := &TwitterConfig{
-			ConsumerKey:    os.Getenv("TWITTER_CLIENT_KEY"),
-			ConsumerSecret: os.Getenv("TWITTER_CLIENT_SECRET"),
-			AccessToken:    identity.AccessToken,
-			AccessSecret:   identity.AccessTokenSecret,
-		}
-		twitterShareRepo := NewTwitterShareRepository(twitterConfig)
-		return twitterShareRepo, nil
+	if s.repositories == nil {
+		return nil, fmt.Errorf("Didn't find repository")
+	}
+	return s.repositories(identity)
+}
 
-	} else if identity.Provider == "linkedin" { // linkedin
-		linkedinShareRepo := NewLinkedinShareRepository(identity)
-		return linkedinShareRepo, nil
+// newShareID returns a random identifier for a share entry
+func newShareID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
 
+// splitTags splits a comma separated list of tags
+func splitTags(tags string) []string {
+	list := make([]string, 0)
+	for _, t := range strings.Split(tags, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			list = append(list, t)
+		}
+	}
+	if len(list) == 0 {
+		return nil
 	}
-	return nil, fmt.Errorf("Didn't find repository")
+	return list
 }
@@ -0,0 +1,89 @@
+package share
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/dorneanu/gocial/internal/config"
+)
+
+// tagURL appends utm_source/utm_medium/utm_campaign to rawURL if its domain
+// is listed in the UTM configuration. Existing query parameters (including
+// already present utm_* parameters) are left untouched.
+func tagURL(rawURL string, provider string, conf config.UTMConfig) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't parse URL: %s", err)
+	}
+
+	domainConf, ok := lookupUTMDomain(u.Hostname(), conf)
+	if !ok {
+		return rawURL, nil
+	}
+
+	// Provider specific parameters take precedence over domain defaults
+	params := config.UTMParams{
+		Source:   provider,
+		Medium:   domainConf.Medium,
+		Campaign: domainConf.Campaign,
+	}
+	if p, ok := domainConf.Providers[provider]; ok {
+		if p.Source != "" {
+			params.Source = p.Source
+		}
+		if p.Medium != "" {
+			params.Medium = p.Medium
+		}
+		if p.Campaign != "" {
+			params.Campaign = p.Campaign
+		}
+	}
+
+	// Append parameters to the raw query so the order and encoding
+	// of the existing ones are preserved
+	existing := u.Query()
+	extra := url.Values{}
+	for key, value := range map[string]string{
+		"utm_source":   params.Source,
+		"utm_medium":   params.Medium,
+		"utm_campaign": params.Campaign,
+	} {
+		if value == "" || existing.Get(key) != "" {
+			continue
+		}
+		extra.Set(key, value)
+	}
+	if len(extra) == 0 {
+		return rawURL, nil
+	}
+
+	if u.RawQuery == "" {
+		u.RawQuery = extra.Encode()
+	} else {
+		u.RawQuery = u.RawQuery + "&" + extra.Encode()
+	}
+	return u.String(), nil
+}
+
+// lookupUTMDomain returns the UTM configuration for host. A configured domain
+// also matches all of its subdomains. If several domains match, the longest
+// (most specific) one wins.
+func lookupUTMDomain(host string, conf config.UTMConfig) (config.UTMDomainConfig, bool) {
+	host = strings.ToLower(host)
+	var (
+		best     string
+		bestConf config.UTMDomainConfig
+		found    bool
+	)
+	for domain, domainConf := range conf.Domains {
+		domain = strings.ToLower(domain)
+		if host != domain && !strings.HasSuffix(host, "."+domain) {
+			continue
+		}
+		if !found || len(domain) > len(best) || (len(domain) == len(best) && domain < best) {
+			best, bestConf, found = domain, domainConf, true
+		}
+	}
+	return bestConf, found
+}
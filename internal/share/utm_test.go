@@ -0,0 +1,73 @@
+package share
+
+import (
+	"testing"
+
+	"github.com/dorneanu/gocial/internal/config"
+)
+
+func TestLookupUTMDomain(t *testing.T) {
+	conf := config.UTMConfig{
+		Domains: map[string]config.UTMDomainConfig{
+			"example.com":      {Campaign: "example"},
+			"blog.example.com": {Campaign: "blog"},
+			"Other.ORG":        {Campaign: "other"},
+		},
+	}
+	tests := []struct {
+		host     string
+		campaign string
+		found    bool
+	}{
+		{host: "example.com", campaign: "example", found: true},
+		{host: "www.example.com", campaign: "example", found: true},
+		{host: "blog.example.com", campaign: "blog", found: true},
+		{host: "www.blog.example.com", campaign: "blog", found: true},
+		{host: "BLOG.example.com", campaign: "blog", found: true},
+		{host: "other.org", campaign: "other", found: true},
+		{host: "notexample.com"},
+		{host: "example.com.evil.net"},
+	}
+	for _, tt := range tests {
+		// Map iteration order is random, repeat to catch order dependence
+		for i := 0; i < 20; i++ {
+			got, found := lookupUTMDomain(tt.host, conf)
+			if found != tt.found || got.Campaign != tt.campaign {
+				t.Fatalf("lookupUTMDomain(%q) = %q, %t; want %q, %t", tt.host, got.Campaign, found, tt.campaign, tt.found)
+			}
+		}
+	}
+}
+
+func TestTagURL(t *testing.T) {
+	conf := config.UTMConfig{
+		Domains: map[string]config.UTMDomainConfig{
+			"example.com": {
+				Medium: "social",
+				Providers: map[string]config.UTMParams{
+					"twitter": {Source: "tw"},
+				},
+			},
+			"blog.example.com": {Medium: "blog"},
+		},
+	}
+	tests := []struct {
+		url      string
+		provider string
+		want     string
+	}{
+		{url: "https://example.com/a", provider: "twitter", want: "https://example.com/a?utm_medium=social&utm_source=tw"},
+		{url: "https://blog.example.com/a", provider: "twitter", want: "https://blog.example.com/a?utm_medium=blog&utm_source=twitter"},
+		{url: "https://blog.example.com/a?x=1&utm_source=own", provider: "linkedin", want: "https://blog.example.com/a?x=1&utm_source=own&utm_medium=blog"},
+		{url: "https://other.org/a", provider: "twitter", want: "https://other.org/a"},
+	}
+	for _, tt := range tests {
+		got, err := tagURL(tt.url, tt.provider, conf)
+		if err != nil {
+			t.Fatalf("tagURL(%q): %s", tt.url, err)
+		}
+		if got != tt.want {
+			t.Errorf("tagURL(%q, %q) = %q; want %q", tt.url, tt.provider, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package shortener
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+const (
+	codeAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	codeLength   = 6
+)
+
+// ErrNotFound is returned when a short code is unknown
+var ErrNotFound = errors.New("Short link not found")
+
+// BuiltinShortener is served by gocial itself at /s/:code. Short links
+// (and their click counts) are kept in a JSON file.
+type BuiltinShortener struct {
+	baseURL   string
+	storePath string
+	mu        sync.Mutex
+}
+
+func NewBuiltinShortener(conf config.BuiltinShortenerConfig) *BuiltinShortener {
+	return &BuiltinShortener{
+		baseURL:   strings.TrimSuffix(conf.BaseURL, "/"),
+		storePath: conf.StorePath,
+	}
+}
+
+// Shorten returns a short link for longURL. Existing links are reused.
+func (b *BuiltinShortener) Shorten(ctx context.Context, longURL string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	links, err := b.load()
+	if err != nil {
+		return "", err
+	}
+	for _, l := range links {
+		if l.URL == longURL {
+			return b.shortURL(l.Code), nil
+		}
+	}
+
+	// Find an unused code
+	var code string
+	for {
+		code, err = newCode()
+		if err != nil {
+			return "", err
+		}
+		if _, ok := links[code]; !ok {
+			break
+		}
+	}
+
+	links[code] = entity.ShortLink{
+		Code:      code,
+		URL:       longURL,
+		CreatedAt: time.Now(),
+	}
+	if err := b.save(links); err != nil {
+		return "", err
+	}
+	return b.shortURL(code), nil
+}
+
+// Preview returns the existing short link of longURL or a new one which
+// isn't stored
+func (b *BuiltinShortener) Preview(ctx context.Context, longURL string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	links, err := b.load()
+	if err != nil {
+		return "", err
+	}
+	for _, l := range links {
+		if l.URL == longURL {
+			return b.shortURL(l.Code), nil
+		}
+	}
+	code, err := newCode()
+	if err != nil {
+		return "", err
+	}
+	return b.shortURL(code), nil
+}
+
+// Resolve returns the long URL for code and counts the click
+func (b *BuiltinShortener) Resolve(code string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	links, err := b.load()
+	if err != nil {
+		return "", err
+	}
+	link, ok := links[code]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	link.Clicks++
+	links[code] = link
+	if err := b.save(links); err != nil {
+		return "", err
+	}
+	return link.URL, nil
+}
+
+func (b *BuiltinShortener) shortURL(code string) string {
+	return fmt.Sprintf("%s/s/%s", b.baseURL, code)
+}
+
+func (b *BuiltinShortener) load() (map[string]entity.ShortLink, error) {
+	links := make(map[string]entity.ShortLink)
+
+	data, err := ioutil.ReadFile(b.storePath)
+	if os.IsNotExist(err) {
+		return links, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("Couldn't open file: %s", err)
+	}
+
+	if err := json.Unmarshal(data, &links); err != nil {
+		return nil, fmt.Errorf("Couldn't unmarshalize data: %s", err)
+	}
+	return links, nil
+}
+
+func (b *BuiltinShortener) save(links map[string]entity.ShortLink) error {
+	data, err := json.MarshalIndent(links, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(b.storePath, data, 0600)
+}
+
+// newCode returns a random short code
+func newCode() (string, error) {
+	code := make([]byte, codeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(codeAlphabet))))
+		if err != nil {
+			return "", fmt.Errorf("Couldn't generate short code: %s", err)
+		}
+		code[i] = codeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
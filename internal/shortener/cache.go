@@ -0,0 +1,60 @@
+package shortener
+
+import (
+	"context"
+	"sync"
+)
+
+// CachedShortener wraps a Shortener and remembers the short URL of every
+// long URL it has already shortened
+type CachedShortener struct {
+	shortener Shortener
+	mu        sync.Mutex
+	cache     map[string]string
+}
+
+func NewCachedShortener(s Shortener) *CachedShortener {
+	return &CachedShortener{
+		shortener: s,
+		cache:     make(map[string]string),
+	}
+}
+
+// Shorten returns the cached short URL or asks the underlying shortener
+func (c *CachedShortener) Shorten(ctx context.Context, longURL string) (string, error) {
+	c.mu.Lock()
+	shortURL, ok := c.cache[longURL]
+	c.mu.Unlock()
+	if ok {
+		return shortURL, nil
+	}
+
+	shortURL, err := c.shortener.Shorten(ctx, longURL)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	c.cache[longURL] = shortURL
+	c.mu.Unlock()
+	return shortURL, nil
+}
+
+// Preview returns the cached short URL or a preview of the underlying
+// shortener. Previews aren't cached.
+func (c *CachedShortener) Preview(ctx context.Context, longURL string) (string, error) {
+	c.mu.Lock()
+	shortURL, ok := c.cache[longURL]
+	c.mu.Unlock()
+	if ok {
+		return shortURL, nil
+	}
+	return Preview(ctx, c.shortener, longURL)
+}
+
+// cachedResolver keeps the Resolver of a shortener served by gocial
+// available after wrapping it into a CachedShortener
+type cachedResolver struct {
+	*CachedShortener
+	Resolver
+}
@@ -0,0 +1,125 @@
+package shortener
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+
+	"github.com/dorneanu/gocial/internal/config"
+)
+
+// RESTShortener talks to a generic shortening API. Both the request URL and
+// the request body are templates which get the (query escaped) long URL
+// passed as {{.URL}} resp. {{.EscapedURL}}.
+type RESTShortener struct {
+	conf   config.RESTShortenerConfig
+	client *http.Client
+}
+
+type restTemplateData struct {
+	URL        string
+	EscapedURL string
+}
+
+func NewRESTShortener(conf config.RESTShortenerConfig) *RESTShortener {
+	return &RESTShortener{
+		conf:   conf,
+		client: &http.Client{},
+	}
+}
+
+// Shorten sends the long URL to the REST API and extracts the short URL
+// from the response
+func (r *RESTShortener) Shorten(ctx context.Context, longURL string) (string, error) {
+	data := restTemplateData{
+		URL:        longURL,
+		EscapedURL: url.QueryEscape(longURL),
+	}
+
+	endpoint, err := render(r.conf.URL, data)
+	if err != nil {
+		return "", err
+	}
+	body, err := render(r.conf.Body, data)
+	if err != nil {
+		return "", err
+	}
+
+	method := r.conf.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	// Create new HTTP request
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, strings.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("Couldn't create request: %s", err)
+	}
+	for k, v := range r.conf.Headers {
+		req.Header.Set(k, v)
+	}
+
+	// Send request
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't shorten URL: %s", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't read response: %s", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("Couldn't shorten URL: %s", resp.Status)
+	}
+
+	// Without a response field the whole body is the short URL
+	if r.conf.ResponseField == "" {
+		return strings.TrimSpace(string(respBody)), nil
+	}
+	return lookupField(respBody, r.conf.ResponseField)
+}
+
+// render executes a text template with the given data
+func render(text string, data interface{}) (string, error) {
+	tmpl, err := template.New("request").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't parse template: %s", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("Couldn't execute template: %s", err)
+	}
+	return buf.String(), nil
+}
+
+// lookupField extracts a string from a JSON document. Nested fields are
+// separated by dots (e.g. "data.link").
+func lookupField(body []byte, field string) (string, error) {
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return "", fmt.Errorf("Couldn't unmarshalize response: %s", err)
+	}
+
+	for _, key := range strings.Split(field, ".") {
+		m, ok := doc.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("Couldn't find field in response: %s", field)
+		}
+		doc = m[key]
+	}
+
+	value, ok := doc.(string)
+	if !ok || value == "" {
+		return "", fmt.Errorf("Couldn't find field in response: %s", field)
+	}
+	return value, nil
+}
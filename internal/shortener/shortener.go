@@ -0,0 +1,58 @@
+package shortener
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dorneanu/gocial/internal/config"
+)
+
+// Shortener turns a long URL into a short one
+type Shortener interface {
+	Shorten(ctx context.Context, longURL string) (string, error)
+}
+
+// Previewer is implemented by shorteners which are able to tell the short
+// URL of a long URL without creating the short link
+type Previewer interface {
+	Preview(ctx context.Context, longURL string) (string, error)
+}
+
+// Preview returns the short URL s would create for longURL. Shorteners which
+// don't implement Previewer are remote services. Asking them would create
+// the link, so longURL is returned unchanged.
+func Preview(ctx context.Context, s Shortener, longURL string) (string, error) {
+	if p, ok := s.(Previewer); ok {
+		return p.Preview(ctx, longURL)
+	}
+	return longURL, nil
+}
+
+// Resolver is implemented by shorteners which are served by gocial itself
+// and are able to map a short code back to the long URL
+type Resolver interface {
+	Resolve(code string) (string, error)
+}
+
+// New returns the shortener specified by the configuration. Results are
+// cached per long URL. If no shortener is configured nil is returned.
+func New(conf config.ShortenerConfig) (Shortener, error) {
+	var s Shortener
+
+	switch conf.Type {
+	case "":
+		return nil, nil
+	case "rest":
+		s = NewRESTShortener(conf.REST)
+	case "yourls":
+		s = NewYourlsShortener(conf.Yourls)
+	case "builtin":
+		s = NewBuiltinShortener(conf.Builtin)
+	default:
+		return nil, fmt.Errorf("Unknown shortener: %s", conf.Type)
+	}
+	if r, ok := s.(Resolver); ok {
+		return cachedResolver{NewCachedShortener(s), r}, nil
+	}
+	return NewCachedShortener(s), nil
+}
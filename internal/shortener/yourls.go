@@ -0,0 +1,68 @@
+package shortener
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dorneanu/gocial/internal/config"
+)
+
+// YourlsShortener uses the API of a YOURLS instance
+//
+// Check out https://yourls.org/#API
+type YourlsShortener struct {
+	conf   config.YourlsShortenerConfig
+	client *http.Client
+}
+
+type yourlsResponse struct {
+	Status   string `json:"status"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	ShortURL string `json:"shorturl"`
+}
+
+func NewYourlsShortener(conf config.YourlsShortenerConfig) *YourlsShortener {
+	return &YourlsShortener{
+		conf:   conf,
+		client: &http.Client{},
+	}
+}
+
+// Shorten creates a new short URL. If the long URL was already shortened
+// YOURLS returns the existing short URL.
+func (y *YourlsShortener) Shorten(ctx context.Context, longURL string) (string, error) {
+	params := url.Values{}
+	params.Set("action", "shorturl")
+	params.Set("format", "json")
+	params.Set("url", longURL)
+	params.Set("signature", y.conf.Signature)
+
+	endpoint := strings.TrimSuffix(y.conf.URL, "/") + "/yourls-api.php"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(params.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("Couldn't create request: %s", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := y.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't shorten URL: %s", err)
+	}
+	defer resp.Body.Close()
+
+	var yourlsResp yourlsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&yourlsResp); err != nil {
+		return "", fmt.Errorf("Couldn't unmarshalize response: %s", err)
+	}
+
+	// "error:url" means the URL already exists and the short URL is still returned
+	if yourlsResp.ShortURL == "" {
+		return "", fmt.Errorf("Couldn't shorten URL: %s", yourlsResp.Message)
+	}
+	return yourlsResp.ShortURL, nil
+}
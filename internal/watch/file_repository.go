@@ -0,0 +1,77 @@
+package watch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+// FileStateRepository implements watch.Repository
+// and keeps the state of all feeds in a single JSON file
+type FileStateRepository struct {
+	BasePath string
+	mu       sync.Mutex
+}
+
+func NewFileStateRepository(path string) *FileStateRepository {
+	return &FileStateRepository{
+		BasePath: path,
+	}
+}
+
+// GetState returns the state of the feed at url. Unknown feeds
+// have an empty state.
+func (fr *FileStateRepository) GetState(url string) (entity.FeedState, error) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	states, err := fr.load()
+	if err != nil {
+		return entity.FeedState{}, err
+	}
+	if state, ok := states[url]; ok {
+		return state, nil
+	}
+	return entity.FeedState{URL: url}, nil
+}
+
+// SaveState stores the state of a feed
+func (fr *FileStateRepository) SaveState(state entity.FeedState) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	states, err := fr.load()
+	if err != nil {
+		return err
+	}
+	states[state.URL] = state
+	return fr.save(states)
+}
+
+func (fr *FileStateRepository) load() (map[string]entity.FeedState, error) {
+	states := make(map[string]entity.FeedState)
+
+	b, err := ioutil.ReadFile(fr.BasePath)
+	if os.IsNotExist(err) {
+		return states, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("Couldn't open file: %s", err)
+	}
+
+	if err := json.Unmarshal(b, &states); err != nil {
+		return nil, fmt.Errorf("Couldn't unmarshalize data: %s", err)
+	}
+	return states, nil
+}
+
+func (fr *FileStateRepository) save(states map[string]entity.FeedState) error {
+	b, err := json.MarshalIndent(states, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fr.BasePath, b, 0600)
+}
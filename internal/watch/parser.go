@@ -0,0 +1,164 @@
+package watch
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/entity"
+)
+
+// rssFeed is the subset of RSS 2.0 gocial cares about
+type rssFeed struct {
+	Items []struct {
+		GUID        string `xml:"guid"`
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+		PubDate     string `xml:"pubDate"`
+	} `xml:"channel>item"`
+}
+
+// atomFeed is the subset of Atom gocial cares about
+type atomFeed struct {
+	Entries []struct {
+		ID    string `xml:"id"`
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Summary   string `xml:"summary"`
+		Content   string `xml:"content"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+	} `xml:"entry"`
+}
+
+// jsonFeed is the subset of JSON Feed gocial cares about
+//
+// Check out https://www.jsonfeed.org/version/1.1/
+type jsonFeed struct {
+	Items []struct {
+		ID            string `json:"id"`
+		URL           string `json:"url"`
+		Title         string `json:"title"`
+		Summary       string `json:"summary"`
+		ContentText   string `json:"content_text"`
+		DatePublished string `json:"date_published"`
+	} `json:"items"`
+}
+
+// parseFeed detects the feed format and returns its entries
+func parseFeed(data []byte) ([]entity.FeedEntry, error) {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		return parseJSONFeed(trimmed)
+	}
+
+	// Find out the name of the root element
+	decoder := xml.NewDecoder(bytes.NewReader(trimmed))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't parse feed: %s", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			switch start.Name.Local {
+			case "rss":
+				return parseRSS(trimmed)
+			case "feed":
+				return parseAtom(trimmed)
+			default:
+				return nil, fmt.Errorf("Unknown feed format: %s", start.Name.Local)
+			}
+		}
+	}
+}
+
+func parseRSS(data []byte) ([]entity.FeedEntry, error) {
+	var feed rssFeed
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		return nil, fmt.Errorf("Couldn't parse RSS feed: %s", err)
+	}
+
+	entries := make([]entity.FeedEntry, 0, len(feed.Items))
+	for _, item := range feed.Items {
+		entries = append(entries, newEntry(item.GUID, item.Title, item.Link, item.Description, item.PubDate))
+	}
+	return entries, nil
+}
+
+func parseAtom(data []byte) ([]entity.FeedEntry, error) {
+	var feed atomFeed
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		return nil, fmt.Errorf("Couldn't parse Atom feed: %s", err)
+	}
+
+	entries := make([]entity.FeedEntry, 0, len(feed.Entries))
+	for _, e := range feed.Entries {
+		var link string
+		for _, l := range e.Links {
+			if l.Rel == "" || l.Rel == "alternate" {
+				link = l.Href
+				break
+			}
+		}
+		summary := e.Summary
+		if summary == "" {
+			summary = e.Content
+		}
+		published := e.Published
+		if published == "" {
+			published = e.Updated
+		}
+		entries = append(entries, newEntry(e.ID, e.Title, link, summary, published))
+	}
+	return entries, nil
+}
+
+func parseJSONFeed(data []byte) ([]entity.FeedEntry, error) {
+	var feed jsonFeed
+	if err := json.Unmarshal(data, &feed); err != nil {
+		return nil, fmt.Errorf("Couldn't parse JSON feed: %s", err)
+	}
+
+	entries := make([]entity.FeedEntry, 0, len(feed.Items))
+	for _, item := range feed.Items {
+		summary := item.Summary
+		if summary == "" {
+			summary = item.ContentText
+		}
+		entries = append(entries, newEntry(item.ID, item.Title, item.URL, summary, item.DatePublished))
+	}
+	return entries, nil
+}
+
+// newEntry creates a feed entry. The link is used as GUID if there is none.
+func newEntry(guid, title, link, summary, published string) entity.FeedEntry {
+	guid = strings.TrimSpace(guid)
+	link = strings.TrimSpace(link)
+	if guid == "" {
+		guid = link
+	}
+	return entity.FeedEntry{
+		GUID:      guid,
+		Title:     strings.TrimSpace(title),
+		Link:      link,
+		Summary:   strings.TrimSpace(summary),
+		Published: parseTime(strings.TrimSpace(published)),
+	}
+}
+
+// parseTime tries the date formats used by RSS, Atom and JSON Feed
+func parseTime(value string) time.Time {
+	for _, layout := range []string{time.RFC3339, time.RFC1123Z, time.RFC1123} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
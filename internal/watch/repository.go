@@ -0,0 +1,9 @@
+package watch
+
+import "github.com/dorneanu/gocial/internal/entity"
+
+// Repository stores the state of watched feeds
+type Repository interface {
+	GetState(string) (entity.FeedState, error)
+	SaveState(entity.FeedState) error
+}
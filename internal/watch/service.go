@@ -0,0 +1,312 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/config"
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/identity"
+	"github.com/dorneanu/gocial/internal/share"
+)
+
+const defaultTemplate = "{{.Title}}"
+
+type Service interface {
+	Poll(context.Context) ([]entity.ArticleShare, error)
+	Run(context.Context)
+}
+
+type ServiceConfig struct {
+	Repo         Repository
+	ShareService share.Service
+	Identities   identity.Repository
+	Feeds        []config.FeedConfig
+	Interval     time.Duration
+	// DryRun only logs what would be shared and doesn't change any state
+	DryRun bool
+}
+
+// watchService implements watch.Service
+type watchService struct {
+	repo         Repository
+	shareService share.Service
+	identities   identity.Repository
+	feeds        []config.FeedConfig
+	interval     time.Duration
+	dryRun       bool
+	client       *http.Client
+}
+
+func NewService(conf ServiceConfig) Service {
+	return watchService{
+		repo:         conf.Repo,
+		shareService: conf.ShareService,
+		identities:   conf.Identities,
+		feeds:        conf.Feeds,
+		interval:     conf.Interval,
+		dryRun:       conf.DryRun,
+		client:       &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Run polls all feeds every interval until ctx is cancelled
+func (s watchService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Poll(ctx); err != nil {
+			log.Printf("%s\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches all feeds once and shares new entries. It returns the
+// articles which were shared (or would have been shared in dry-run mode).
+func (s watchService) Poll(ctx context.Context) ([]entity.ArticleShare, error) {
+	shared := make([]entity.ArticleShare, 0)
+	var errs []string
+
+	for _, feed := range s.feeds {
+		articles, err := s.pollFeed(ctx, feed)
+		shared = append(shared, articles...)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", feed.URL, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return shared, fmt.Errorf("Couldn't poll feeds: %s", strings.Join(errs, "; "))
+	}
+	return shared, nil
+}
+
+func (s watchService) pollFeed(ctx context.Context, feed config.FeedConfig) ([]entity.ArticleShare, error) {
+	shared := make([]entity.ArticleShare, 0)
+
+	state, err := s.repo.GetState(feed.URL)
+	if err != nil {
+		return shared, err
+	}
+
+	// Scheduled entries are still shared if the feed can't be fetched
+	entries, validators, notModified, fetchErr := s.fetch(ctx, state)
+	if fetchErr == nil && !notModified {
+		articles, complete := s.handleEntries(feed, &state, entries)
+		shared = append(shared, articles...)
+
+		// The validators of the response are only kept if every new entry
+		// was handled. Otherwise the next poll would get a 304 and never retry.
+		if complete {
+			state.ETag = validators.ETag
+			state.LastModified = validators.LastModified
+		}
+	}
+	shared = append(shared, s.sharePending(&state)...)
+
+	if s.dryRun {
+		return shared, fetchErr
+	}
+	if fetchErr == nil {
+		state.LastPolled = time.Now()
+	}
+	if err := s.repo.SaveState(state); err != nil {
+		return shared, err
+	}
+	return shared, fetchErr
+}
+
+// handleEntries shares or schedules the new entries of feed and marks them
+// as seen in state. It returns false if some entries have to be retried.
+func (s watchService) handleEntries(feed config.FeedConfig, state *entity.FeedState, entries []entity.FeedEntry) ([]entity.ArticleShare, bool) {
+	shared := make([]entity.ArticleShare, 0)
+
+	// Entries present on the first poll are only marked as seen.
+	// Otherwise the whole feed would be shared at once.
+	if state.LastPolled.IsZero() {
+		if s.dryRun {
+			log.Printf("[dry-run] Would mark %d existing entries of %s as seen\n", len(entries), feed.URL)
+			return shared, true
+		}
+		for _, entry := range entries {
+			if !state.HasSeen(entry.GUID) {
+				state.Seen = append(state.Seen, entry.GUID)
+			}
+		}
+		return shared, true
+	}
+
+	complete := true
+	for _, entry := range entries {
+		if state.HasSeen(entry.GUID) {
+			continue
+		}
+
+		// A broken entry must not hold back the others. It isn't marked
+		// as seen so it's shared once the template is fixed.
+		article, err := newArticle(entry, feed)
+		if err != nil {
+			log.Printf("Skipping entry %s of %s: %s\n", entry.GUID, feed.URL, err)
+			complete = false
+			continue
+		}
+
+		if feed.Delay > 0 {
+			dueAt := time.Now().Add(feed.Delay)
+			if s.dryRun {
+				log.Printf("[dry-run] Would schedule %s to %s at %s\n", article.URL, article.Providers, dueAt.Format(time.RFC3339))
+				continue
+			}
+			state.Pending = append(state.Pending, entity.PendingShare{
+				GUID:    entry.GUID,
+				Article: article,
+				DueAt:   dueAt,
+			})
+			state.Seen = append(state.Seen, entry.GUID)
+			continue
+		}
+
+		if s.dryRun {
+			log.Printf("[dry-run] Would share %s to %s\n", article.URL, article.Providers)
+			shared = append(shared, article)
+			continue
+		}
+
+		// Retry next time if no provider accepted the article
+		if s.share(article) {
+			shared = append(shared, article)
+			state.Seen = append(state.Seen, entry.GUID)
+		} else {
+			complete = false
+		}
+	}
+	return shared, complete
+}
+
+// sharePending shares the scheduled entries of state which are due. Entries
+// no provider accepted are kept and retried on the next poll.
+func (s watchService) sharePending(state *entity.FeedState) []entity.ArticleShare {
+	shared := make([]entity.ArticleShare, 0)
+	pending := make([]entity.PendingShare, 0, len(state.Pending))
+	now := time.Now()
+
+	for _, p := range state.Pending {
+		if p.DueAt.After(now) {
+			pending = append(pending, p)
+			continue
+		}
+		if s.dryRun {
+			log.Printf("[dry-run] Would share scheduled %s to %s\n", p.Article.URL, p.Article.Providers)
+			shared = append(shared, p.Article)
+			pending = append(pending, p)
+			continue
+		}
+		if s.share(p.Article) {
+			shared = append(shared, p.Article)
+		} else {
+			pending = append(pending, p)
+		}
+	}
+	state.Pending = pending
+	return shared
+}
+
+// share sends article to all of its providers and returns true if at
+// least one provider succeeded
+func (s watchService) share(article entity.ArticleShare) bool {
+	succeeded := false
+	for _, provider := range strings.Split(article.Providers, ",") {
+		id, err := s.identities.GetByProvider(provider, nil)
+		if err != nil {
+			log.Printf("Couldn't get identity for %s: %s\n", provider, err)
+			continue
+		}
+		if _, err := s.shareService.ShareArticle(article, id); err != nil {
+			log.Printf("Couldn't share %s to %s: %s\n", article.URL, provider, err)
+			continue
+		}
+		succeeded = true
+	}
+	return succeeded
+}
+
+// fetch downloads the feed using a conditional GET with the ETag and
+// Last-Modified values of state. It returns the entries, the validators of
+// the response and whether the feed was not modified.
+func (s watchService) fetch(ctx context.Context, state entity.FeedState) ([]entity.FeedEntry, entity.FeedState, bool, error) {
+	var validators entity.FeedState
+	req, err := http.NewRequestWithContext(ctx, "GET", state.URL, nil)
+	if err != nil {
+		return nil, validators, false, fmt.Errorf("Couldn't create request: %s", err)
+	}
+	if state.ETag != "" {
+		req.Header.Set("If-None-Match", state.ETag)
+	}
+	if state.LastModified != "" {
+		req.Header.Set("If-Modified-Since", state.LastModified)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, validators, false, fmt.Errorf("Couldn't fetch feed: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, validators, true, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, validators, false, fmt.Errorf("Couldn't fetch feed: %s", resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, validators, false, fmt.Errorf("Couldn't read feed: %s", err)
+	}
+	entries, err := parseFeed(body)
+	if err != nil {
+		return nil, validators, false, err
+	}
+
+	validators.ETag = resp.Header.Get("ETag")
+	validators.LastModified = resp.Header.Get("Last-Modified")
+	return entries, validators, false, nil
+}
+
+// newArticle builds an article share from a feed entry
+func newArticle(entry entity.FeedEntry, feed config.FeedConfig) (entity.ArticleShare, error) {
+	text := feed.Template
+	if text == "" {
+		text = defaultTemplate
+	}
+
+	tmpl, err := template.New("comment").Parse(text)
+	if err != nil {
+		return entity.ArticleShare{}, fmt.Errorf("Couldn't parse template: %s", err)
+	}
+	var comment bytes.Buffer
+	if err := tmpl.Execute(&comment, entry); err != nil {
+		return entity.ArticleShare{}, fmt.Errorf("Couldn't execute template: %s", err)
+	}
+
+	return entity.ArticleShare{
+		URL:       entry.Link,
+		Title:     entry.Title,
+		Comment:   strings.TrimSpace(comment.String()),
+		Providers: strings.Join(feed.Providers, ","),
+	}, nil
+}
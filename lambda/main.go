@@ -2,15 +2,15 @@ package main
 
 import (
 	"context"
-	"fmt"
+	_ "embed"
+	"log"
 	"os"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	echoadapter "github.com/awslabs/aws-lambda-go-api-proxy/echo"
-	"github.com/dorneanu/gocial/internal/identity"
-	"github.com/dorneanu/gocial/internal/oauth"
-	"github.com/dorneanu/gocial/internal/share"
+	"github.com/dorneanu/gocial/internal/bootstrap"
+	"github.com/dorneanu/gocial/internal/config"
 	"github.com/dorneanu/gocial/server"
 	"github.com/labstack/echo/v4"
 )
@@ -24,58 +24,34 @@ var (
 // 	return echoLambda.ProxyWithContext(ctx, req)
 // }
 
+// lambdaConfig is used unless GOCIAL_CONFIG points to a configuration file
+//
+//go:embed gocial.yaml
+var lambdaConfig []byte
+
 func init() {
 	// stdout and stderr are sent to AWS CloudWatch Logs
 	e := echo.New()
 
-	webServerConf := server.HTTPServerConfig{
-		ListenAddr:      "gocial.netlify.app",
-		TokenSigningKey: "secret key",
-		TokenExpiration: 5,
+	var conf *config.Config
+	var err error
+	if file := os.Getenv("GOCIAL_CONFIG"); file != "" {
+		conf, err = config.Load(file)
+	} else {
+		conf, err = config.Parse(lambdaConfig)
 	}
-	// TODO: Is this still needed
-	// idRepo := entity.NewFileIdentityRepo("./auth.yaml")
-
-	// Create OAuth configs for different providers
-	oauthConfigs := []oauth.OAuthConfig{
-		oauth.OAuthConfig{
-			ProviderName: "linkedin",
-			Scopes:       []string{"r_emailaddress", "r_liteprofile", "w_member_social"},
-			ClientID:     os.Getenv("LINKEDIN_CLIENT_ID"),
-			ClientSecret: os.Getenv("LINKEDIN_CLIENT_SECRET"),
-			CallbackURL:  fmt.Sprintf("https://%s/auth/callback/linkedin", webServerConf.ListenAddr),
-		},
-		oauth.OAuthConfig{
-			ProviderName: "twitter",
-			ClientID:     os.Getenv("TWITTER_CLIENT_KEY"),
-			ClientSecret: os.Getenv("TWITTER_CLIENT_SECRET"),
-			CallbackURL:  fmt.Sprintf("http://%s/auth/callback/twitter", webServerConf.ListenAddr),
-		},
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	// New goth auth repository
-	providerIndex := oauth.SetupAuthProviders(oauthConfigs)
-	gothRepository := oauth.NewGothRepository(providerIndex, webServerConf.TokenSigningKey)
-
-	// New identity repository
-	cookieIdentityRepo := identity.NewCookieIdentityRepository(&identity.CookieIdentityOptions{
-		BaseCookieName:  "gocial",
-		TokenSigningKey: webServerConf.TokenSigningKey,
-	})
-
-	// New OAuth authentication service service
-	oauthService := oauth.NewService(
-		oauth.ServiceConfig{
-			Repo:          gothRepository,
-			ProviderIndex: providerIndex,
-		},
-	)
-
-	// New share service
-	webServerConf.OAuthService = oauthService
-	webServerConf.IdentityService = cookieIdentityRepo
-	webServerConf.ProviderIndex = &providerIndex
-	webServerConf.ShareService = share.NewShareService()
+	app, err := bootstrap.New(conf)
+	if err != nil {
+		log.Fatal(err)
+	}
+	webServerConf, err := app.HTTPServerConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// New web server
 	httpServer := server.NewHTTPService(webServerConf)
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dorneanu/gocial/internal/analytics"
+	"github.com/dorneanu/gocial/internal/provider"
+	"github.com/labstack/echo/v4"
+)
+
+// handleTrackingLink records a click and redirects to the shared URL
+func (h httpServer) handleTrackingLink(c echo.Context) error {
+	req := c.Request()
+	longURL, err := h.conf.AnalyticsService.Click(c.Param("code"), req.Referer(), req.UserAgent())
+	if errors.Is(err, analytics.ErrLinkNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.Redirect(http.StatusFound, longURL)
+}
+
+// handleAnalyticsIndex shows the analytics dashboard
+func (h httpServer) handleAnalyticsIndex(c echo.Context) error {
+	return c.Render(http.StatusOK, "analyticsIndex", nil)
+}
+
+// requireOperator only passes requests of the configured operators. Analytics
+// cover the shares of all users.
+func (h httpServer) requireOperator(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if r, ok := h.identityService.(provider.IdentityRepository); !ok || !r.IsOperator(c) {
+			return echo.NewHTTPError(http.StatusForbidden, "Only available to operators")
+		}
+		return next(c)
+	}
+}
@@ -1,10 +1,14 @@
 package server
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/provider"
+	"github.com/dorneanu/gocial/internal/share"
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 )
@@ -29,6 +33,15 @@ func (h httpServer) registerAPIRoutes(routerGroup *echo.Group) {
 	// Setup routes
 	routerGroup.POST("/share", h.handleAPIShare)
 	routerGroup.GET("/providers", h.handleAPIGetProviders)
+	routerGroup.PUT("/shares/:id", h.handleAPIEditShare)
+	routerGroup.DELETE("/shares/:id", h.handleAPIDeleteShare)
+	if h.conf.AnalyticsService != nil {
+		routerGroup.GET("/analytics", h.handleAPIGetAnalytics, h.requireOperator)
+	}
+	if h.conf.MetricsService != nil {
+		routerGroup.POST("/metrics", h.handleAPICollectMetrics)
+		routerGroup.GET("/shares/:id/metrics", h.handleAPIGetShareMetrics)
+	}
 }
 
 // handleAPIShare ...
@@ -50,6 +63,9 @@ func (h httpServer) handleAPIShare(c echo.Context) error {
 
 	// Get provider (URL parameter)
 	providers := strings.Split(articleShare.Providers, ",")
+	shares := make([]entity.ShareEntry, 0, len(providers))
+	previews := make([]entity.SharePreview, 0, len(providers))
+	dryRun, _ := strconv.ParseBool(c.QueryParam("dry_run"))
 
 	for _, provider := range providers {
 		// Try to fetch an identity provider from the identity service
@@ -60,34 +76,171 @@ func (h httpServer) handleAPIShare(c echo.Context) error {
 				"provider": provider,
 			})
 		}
-		shareRepo, err := h.shareService.GetShareRepo(idProvider)
-		if err != nil {
-			return echo.NewHTTPError(http.StatusBadRequest, echo.Map{
-				"error":    err.Error(),
-				"provider": provider,
-			})
+		// Only show what would be sent
+		if dryRun {
+			preview, err := h.shareService.PreviewArticle(*articleShare, idProvider)
+			if err != nil {
+				return shareError(err, idProvider.Provider)
+			}
+			previews = append(previews, preview)
+			continue
 		}
+
 		// Share article
-		if err := h.shareService.ShareArticle(*articleShare, shareRepo); err != nil {
-			return echo.NewHTTPError(http.StatusBadRequest, echo.Map{
-				"error":    err.Error(),
-				"provider": idProvider.Provider,
-			})
+		entry, err := h.shareService.ShareArticle(*articleShare, idProvider)
+		if err != nil {
+			return shareError(err, idProvider.Provider)
 		}
+		shares = append(shares, entry)
 	}
-	return c.JSON(http.StatusOK, articleShare)
+	if dryRun {
+		return c.JSON(http.StatusOK, echo.Map{
+			"article":  articleShare,
+			"previews": previews,
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"article": articleShare,
+		"shares":  shares,
+	})
 }
 
-// handleAPIGetProviders ...
+// handleAPIEditShare changes title and comment of an already published post
+func (h httpServer) handleAPIEditShare(c echo.Context) error {
+	entry, idProvider, err := h.shareWithIdentity(c)
+	if err != nil {
+		return err
+	}
+
+	article := entity.ArticleShare{}
+	if err := c.Bind(&article); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := h.shareService.EditShare(entry, article, idProvider); err != nil {
+		return shareError(err, entry.Provider)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// handleAPIDeleteShare deletes an already published post
+func (h httpServer) handleAPIDeleteShare(c echo.Context) error {
+	entry, idProvider, err := h.shareWithIdentity(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.shareService.DeleteShare(entry, idProvider); err != nil {
+		return shareError(err, entry.Provider)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// shareWithIdentity looks up the share given by the "id" URL parameter
+// and the identity of its provider. The share must have been published with
+// that identity.
+func (h httpServer) shareWithIdentity(c echo.Context) (entity.ShareEntry, entity.IdentityProvider, error) {
+	entry, err := h.shareService.GetShare(c.Param("id"))
+	if err != nil {
+		return entry, entity.IdentityProvider{}, echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+
+	idProvider, err := h.identityService.GetByProvider(entry.Provider, c)
+	if err != nil {
+		return entry, idProvider, echo.NewHTTPError(http.StatusBadRequest, echo.Map{
+			"error":    err.Error(),
+			"provider": entry.Provider,
+		})
+	}
+	if !ownsShare(entry, idProvider) {
+		return entry, idProvider, echo.NewHTTPError(http.StatusForbidden, echo.Map{
+			"error":    "Share was published by another account",
+			"provider": entry.Provider,
+		})
+	}
+	return entry, idProvider, nil
+}
+
+// ownsShare reports whether entry was published with the account of id.
+// Shares of providers without an identity belong to all operators (only
+// they get such an identity).
+func ownsShare(entry entity.ShareEntry, id entity.IdentityProvider) bool {
+	if p, ok := provider.Get(entry.Provider); ok && p.NoIdentity {
+		return true
+	}
+	if entry.AccountID != "" {
+		return entry.AccountID == id.UserID
+	}
+	// Shares recorded before account IDs were stored
+	return entry.Account != "" && entry.Account == id.UserName
+}
+
+// shareError converts errors of the share service into HTTP errors
+func shareError(err error, provider string) error {
+	status := http.StatusBadRequest
+	if errors.Is(err, share.ErrNotSupported) {
+		status = http.StatusNotImplemented
+	} else if errors.Is(err, share.ErrDuplicate) {
+		status = http.StatusConflict
+	} else if errors.Is(err, share.ErrDeleted) {
+		status = http.StatusGone
+	}
+	return echo.NewHTTPError(status, echo.Map{
+		"error":    err.Error(),
+		"provider": provider,
+	})
+}
+
+// providerIdentity is an identity together with the metadata of its provider
+type providerIdentity struct {
+	entity.IdentityProvider
+	Info entity.ProviderInfo
+}
+
+// handleAPIGetProviders returns all identities the user is logged in with
 func (h httpServer) handleAPIGetProviders(c echo.Context) error {
-	providers := make([]entity.IdentityProvider, 0)
+	providers := make([]providerIdentity, 0)
 
 	for _, ip := range h.availableIdentityProviders(c) {
-		provider, err := h.identityService.GetByProvider(ip.Provider, c)
+		id, err := h.identityService.GetByProvider(ip.Provider, c)
 		if err != nil {
 			continue
 		}
-		providers = append(providers, provider)
+		p, _ := provider.Get(id.Provider)
+		providers = append(providers, providerIdentity{
+			IdentityProvider: id,
+			Info:             p.ProviderInfo,
+		})
 	}
 	return c.JSONPretty(http.StatusOK, providers, "  ")
 }
+
+// handleAPIGetAnalytics returns aggregated click counts of tracking links
+func (h httpServer) handleAPIGetAnalytics(c echo.Context) error {
+	summary, err := h.conf.AnalyticsService.Summary()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSONPretty(http.StatusOK, summary, "  ")
+}
+
+// handleAPICollectMetrics collects metrics of all shares using the current identities
+func (h httpServer) handleAPICollectMetrics(c echo.Context) error {
+	snapshots, err := h.conf.MetricsService.Collect(c.Request().Context(), h.availableIdentityProviders(c))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadGateway, echo.Map{
+			"error":     err.Error(),
+			"snapshots": snapshots,
+		})
+	}
+	return c.JSONPretty(http.StatusOK, snapshots, "  ")
+}
+
+// handleAPIGetShareMetrics returns the metrics time series of a share
+func (h httpServer) handleAPIGetShareMetrics(c echo.Context) error {
+	series, err := h.conf.MetricsService.TimeSeries(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSONPretty(http.StatusOK, series, "  ")
+}
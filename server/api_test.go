@@ -0,0 +1,33 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/provider"
+)
+
+func init() {
+	provider.Register(provider.Provider{ProviderInfo: entity.ProviderInfo{Name: "test-oauth"}})
+	provider.Register(provider.Provider{ProviderInfo: entity.ProviderInfo{Name: "test-hook"}, NoIdentity: true})
+}
+
+func TestOwnsShare(t *testing.T) {
+	alice := entity.IdentityProvider{Provider: "test-oauth", UserID: "1", UserName: "alice"}
+	tests := []struct {
+		name  string
+		entry entity.ShareEntry
+		owns  bool
+	}{
+		{name: "same account", entry: entity.ShareEntry{Provider: "test-oauth", AccountID: "1", Account: "old-name"}, owns: true},
+		{name: "other account", entry: entity.ShareEntry{Provider: "test-oauth", AccountID: "2", Account: "alice"}},
+		{name: "legacy entry of same name", entry: entity.ShareEntry{Provider: "test-oauth", Account: "alice"}, owns: true},
+		{name: "legacy entry without account", entry: entity.ShareEntry{Provider: "test-oauth"}},
+		{name: "provider without identity", entry: entity.ShareEntry{Provider: "test-hook"}, owns: true},
+	}
+	for _, tt := range tests {
+		if got := ownsShare(tt.entry, alice); got != tt.owns {
+			t.Errorf("%s: ownsShare = %t; want %t", tt.name, got, tt.owns)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dorneanu/gocial/internal/feed"
+	"github.com/labstack/echo/v4"
+)
+
+// feedFormats maps the feed routes to their content type
+var feedFormats = map[string]string{
+	"atom": "application/atom+xml; charset=utf-8",
+	"rss":  "application/rss+xml; charset=utf-8",
+	"json": "application/feed+json; charset=utf-8",
+}
+
+// handleFeed takes care of GET "/feed.<format>". The feed can be filtered
+// by the query parameters provider, account and tag.
+func (h httpServer) handleFeed(format string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		f, err := h.conf.FeedService.Feed(feed.Filter{
+			Provider: c.QueryParam("provider"),
+			Account:  c.QueryParam("account"),
+			Tag:      c.QueryParam("tag"),
+		})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+
+		self := strings.TrimSuffix(f.Link, "/") + req.URL.RequestURI()
+		var body []byte
+		switch format {
+		case "atom":
+			body, err = f.Atom(self)
+		case "rss":
+			body, err = f.RSS(self)
+		default:
+			body, err = f.JSON(self)
+		}
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+
+		sum := sha256.Sum256(body)
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		header := c.Response().Header()
+		header.Set("ETag", etag)
+		header.Set("Cache-Control", "public, max-age=300")
+		if !f.Updated.IsZero() {
+			header.Set("Last-Modified", f.Updated.UTC().Format(http.TimeFormat))
+		}
+		if notModified(req, etag, f.Updated) {
+			return c.NoContent(http.StatusNotModified)
+		}
+		return c.Blob(http.StatusOK, feedFormats[format], body)
+	}
+}
+
+// notModified reports whether the client's cached copy is still valid.
+// If-None-Match takes precedence over If-Modified-Since.
+func notModified(req *http.Request, etag string, updated time.Time) bool {
+	if match := req.Header.Get("If-None-Match"); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == etag || tag == "*" {
+				return true
+			}
+		}
+		return false
+	}
+
+	since, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil || updated.IsZero() {
+		return false
+	}
+	return !updated.Truncate(time.Second).After(since)
+}
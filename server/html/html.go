@@ -72,6 +72,7 @@ func RegisterTemplates() *TemplateRegistry {
 	templates["authIndex"] = parse("templates/auth/index.html")
 	templates["authInfo"] = parse("templates/auth/info.html")
 	templates["shareIndex"] = parse("templates/share/index.html")
+	templates["analyticsIndex"] = parse("templates/analytics/index.html")
 
 	return &TemplateRegistry{
 		templates: templates,
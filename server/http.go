@@ -1,14 +1,22 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"html/template"
 	"io"
 	"net/http"
+	"time"
 
+	"github.com/dorneanu/gocial/internal/analytics"
 	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/feed"
 	"github.com/dorneanu/gocial/internal/identity"
+	"github.com/dorneanu/gocial/internal/metrics"
 	"github.com/dorneanu/gocial/internal/oauth"
 	"github.com/dorneanu/gocial/internal/share"
+	"github.com/dorneanu/gocial/internal/shortener"
+	"github.com/dorneanu/gocial/internal/watch"
 	"github.com/dorneanu/gocial/server/html"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -24,13 +32,20 @@ func (t *Template) Render(w io.Writer, name string, data interface{}, c echo.Con
 
 // HTTPServerConfig holds information how to run the HTTP server
 type HTTPServerConfig struct {
-	ListenAddr      string
-	TokenSigningKey string
-	TokenExpiration int
-	ShareService    share.Service
-	OAuthService    oauth.Service
-	IdentityService identity.Repository
-	ProviderIndex   *entity.AuthProviderIndex
+	ListenAddr       string
+	TokenSigningKey  string
+	TokenExpiration  time.Duration
+	ShareService     share.Service
+	OAuthService     oauth.Service
+	IdentityService  identity.Repository
+	ProviderIndex    *entity.AuthProviderIndex
+	Shortener        shortener.Shortener
+	AnalyticsService analytics.Service
+	MetricsService   metrics.Service
+	// FeedService publishes the share history as feeds if set
+	FeedService feed.Service
+	// WatchService is run as a background job if set
+	WatchService watch.Service
 }
 
 type httpServer struct {
@@ -80,6 +95,24 @@ func (h httpServer) Start(e *echo.Echo) {
 	shareGroup := e.Group("/share")
 	h.registerShareRoutes(shareGroup)
 
+	// Serve short links if the shortener is hosted by gocial
+	if _, ok := h.conf.Shortener.(shortener.Resolver); ok {
+		e.GET("/s/:code", h.handleShortLink)
+	}
+
+	// Serve tracking links and analytics dashboard
+	if h.conf.AnalyticsService != nil {
+		e.GET("/t/:code", h.handleTrackingLink)
+		e.GET("/analytics/", h.handleAnalyticsIndex, h.requireOperator)
+	}
+
+	// Serve the share history as Atom, RSS and JSON feed
+	if h.conf.FeedService != nil {
+		e.GET("/feed.atom", h.handleFeed("atom"))
+		e.GET("/feed.rss", h.handleFeed("rss"))
+		e.GET("/feed.json", h.handleFeed("json"))
+	}
+
 	// Create routing group for the REST API
 	apiGroup := e.Group("/api")
 	h.registerAPIRoutes(apiGroup)
@@ -87,6 +120,11 @@ func (h httpServer) Start(e *echo.Echo) {
 	// Setup static content
 	staticContentHandler := echo.WrapHandler(http.FileServer(http.FS(html.StaticContent)))
 	e.GET("/static/*", staticContentHandler)
+
+	// Start background jobs
+	if h.conf.WatchService != nil {
+		go h.conf.WatchService.Run(context.Background())
+	}
 }
 
 // handleIndex takes care of GET "/"
@@ -94,6 +132,17 @@ func (h httpServer) handleIndex(c echo.Context) error {
 	return c.Render(http.StatusOK, "about", nil)
 }
 
+// handleShortLink redirects a short link to its long URL
+func (h httpServer) handleShortLink(c echo.Context) error {
+	longURL, err := h.conf.Shortener.(shortener.Resolver).Resolve(c.Param("code"))
+	if errors.Is(err, shortener.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.Redirect(http.StatusFound, longURL)
+}
+
 func (h httpServer) handleAbout(c echo.Context) error {
 	return c.Render(http.StatusOK, "about", nil)
 }
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/dorneanu/gocial/internal/entity"
+	"github.com/dorneanu/gocial/internal/provider"
 	"github.com/dorneanu/gocial/server/html"
 	"github.com/labstack/echo/v4"
 )
@@ -64,11 +65,12 @@ func (h httpServer) handleOAuthCallback(c echo.Context) error {
 	return c.Redirect(http.StatusTemporaryRedirect, "/auth/info")
 }
 
-// availableIdentityProviders returns a list of all available identity providers
+// availableIdentityProviders returns the identities of all registered
+// providers the user is logged in with
 func (h httpServer) availableIdentityProviders(c echo.Context) []entity.IdentityProvider {
 	var identityProviders []entity.IdentityProvider
 
-	for _, p := range h.providerIndex.Providers {
+	for _, p := range provider.Names() {
 		// Try to fetch an identity provider from the identity service
 		idProvider, err := h.identityService.GetByProvider(p, c)
 		if err != nil {